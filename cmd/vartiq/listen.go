@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq/replay"
+)
+
+func (c *cli) listen(ctx context.Context, args []string) error {
+	fs := c.flagSet("listen")
+	addr := fs.String("addr", "localhost:8080", "`address` to listen on")
+	path := fs.String("path", "/", "URL path that receives deliveries")
+	secret := fs.String("secret", "", "webhook secret used to verify deliveries")
+	webhookID := fs.String("webhook", "", "webhook `ID` to fetch the secret from instead of --secret")
+	forward := fs.String("forward", "", "`URL` to forward verified deliveries to; the path and query of each delivery are appended")
+	record := fs.String("record", "", "`file` to append verified deliveries to, one JSON object per line")
+	if _, err := c.parse(fs, args); err != nil {
+		return err
+	}
+	if (*secret == "") == (*webhookID == "") {
+		return usageError("set exactly one of --secret and --webhook")
+	}
+	if *webhookID != "" {
+		var err error
+		if *secret, err = c.webhookSecret(ctx, *webhookID); err != nil {
+			return err
+		}
+	}
+
+	l := &listener{out: c.stdout, secret: *secret, forward: *forward}
+	if *record != "" {
+		archive, err := replay.OpenArchive(*record)
+		if err != nil {
+			return err
+		}
+		defer archive.Close()
+		l.record = archive
+	}
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle(*path, l.handler())
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(c.stderr, "Listening for deliveries on http://%s%s\n", ln.Addr(), *path)
+	if *forward != "" {
+		fmt.Fprintf(c.stderr, "Forwarding verified deliveries to %s\n", *forward)
+	}
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// recorder stores verified deliveries; *replay.Archive implements it
+type recorder interface {
+	Record(d *vartiq.Delivery) error
+}
+
+// listener prints, records and forwards the deliveries received by
+// vartiq listen
+type listener struct {
+	out     io.Writer
+	secret  string
+	forward string
+	record  recorder
+
+	mu sync.Mutex
+}
+
+func (l *listener) handler() http.Handler {
+	mw := vartiq.ReceiverMiddleware(l.secret,
+		vartiq.OnDelivery(l.delivered),
+		vartiq.OnReject(l.rejected),
+	)
+	return mw(http.HandlerFunc(l.serve))
+}
+
+func (l *listener) delivered(d *vartiq.Delivery) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.printDelivery(d, "verified")
+	if l.record == nil {
+		return
+	}
+	if err := l.record.Record(d); err != nil {
+		fmt.Fprintf(l.out, "  ! failed to record delivery: %v\n", err)
+	}
+}
+
+func (l *listener) rejected(d *vartiq.Delivery, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.printDelivery(d, "REJECTED")
+	if d.Body == nil {
+		fmt.Fprintf(l.out, "  ! %v\n", err)
+		return
+	}
+	v := diagnose(d.Body, d.Signature(), l.secret)
+	fmt.Fprintf(l.out, "  ! %s\n", v.Reason)
+	for _, hint := range v.Hints {
+		fmt.Fprintf(l.out, "    - %s\n", hint)
+	}
+}
+
+// printDelivery must be called with l.mu held
+func (l *listener) printDelivery(d *vartiq.Delivery, result string) {
+	fmt.Fprintf(l.out, "%s %s %s (%s)\n", d.ReceivedAt.Local().Format("15:04:05.000"), d.Method, d.Path, result)
+	names := make([]string, 0, len(d.Header))
+	for name := range d.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(l.out, "  %s: %s\n", name, strings.Join(d.Header[name], ", "))
+	}
+
+	var pretty bytes.Buffer
+	if json.Indent(&pretty, d.Body, "  ", "  ") == nil {
+		fmt.Fprintf(l.out, "\n  %s\n\n", pretty.String())
+	} else if len(d.Body) > 0 {
+		fmt.Fprintf(l.out, "\n  %s\n\n", d.Body)
+	}
+}
+
+func (l *listener) serve(w http.ResponseWriter, r *http.Request) {
+	if l.forward == "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Forward the exact bytes that were verified so the signature still
+	// matches downstream
+	var body []byte
+	if d, ok := vartiq.DeliveryFrom(r.Context()); ok {
+		body = d.Body
+	}
+	target, err := forwardURL(l.forward, r.URL)
+	var req *http.Request
+	if err == nil {
+		req, err = http.NewRequestWithContext(r.Context(), r.Method, target, bytes.NewReader(body))
+	}
+	if err == nil {
+		req.Header = r.Header.Clone()
+		req.Header.Del("Content-Length")
+		var resp *http.Response
+		if resp, err = http.DefaultClient.Do(req); err == nil {
+			defer resp.Body.Close()
+			l.printf("  -> forwarded to %s: %s\n", target, resp.Status)
+			for name, values := range resp.Header {
+				w.Header()[name] = values
+			}
+			w.WriteHeader(resp.StatusCode)
+			io.Copy(w, resp.Body)
+			return
+		}
+	}
+	l.printf("  -> forwarding to %s failed: %v\n", l.forward, err)
+	http.Error(w, "forwarding failed", http.StatusBadGateway)
+}
+
+// forwardURL appends the path and query of a delivery to the --forward URL
+func forwardURL(forward string, delivery *url.URL) (string, error) {
+	u, err := url.Parse(forward)
+	if err != nil {
+		return "", err
+	}
+	if delivery.Path != "/" {
+		u = u.JoinPath(delivery.Path)
+	}
+	switch {
+	case delivery.RawQuery == "":
+	case u.RawQuery == "":
+		u.RawQuery = delivery.RawQuery
+	default:
+		u.RawQuery += "&" + delivery.RawQuery
+	}
+	return u.String(), nil
+}
+
+func (l *listener) printf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.out, format, args...)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq/replay"
+)
+
+type memoryRecorder struct {
+	deliveries []*vartiq.Delivery
+}
+
+func (m *memoryRecorder) Record(d *vartiq.Delivery) error {
+	m.deliveries = append(m.deliveries, d)
+	return nil
+}
+
+func postDelivery(t *testing.T, url, body, sig string) *http.Response {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set(vartiq.SignatureHeader, sig)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestListener_PrintsAndForwards(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, verifyBody, string(body))
+		assert.Equal(t, signature(verifyBody, "s3cret"), r.Header.Get(vartiq.SignatureHeader))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer target.Close()
+
+	var out bytes.Buffer
+	record := &memoryRecorder{}
+	l := &listener{out: &out, secret: "s3cret", forward: target.URL, record: record}
+	server := httptest.NewServer(l.handler())
+	defer server.Close()
+
+	resp := postDelivery(t, server.URL+"/hooks", verifyBody, signature(verifyBody, "s3cret"))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	assert.Contains(t, out.String(), "POST /hooks (verified)")
+	assert.Contains(t, out.String(), "Content-Type: application/json")
+	assert.Contains(t, out.String(), `"event": "order.created"`)
+	assert.Contains(t, out.String(), "-> forwarded to "+target.URL+"/hooks: 202 Accepted")
+
+	require.Len(t, record.deliveries, 1)
+	assert.Equal(t, verifyBody, string(record.deliveries[0].Body))
+}
+
+func TestListener_ForwardsVerifiableDeliveries(t *testing.T) {
+	// Whitespace and key order a re-encoding would change
+	const body = `{"id": 42,   "event":"order.created"}`
+	var path, query string
+	target := httptest.NewServer(vartiq.ReceiverMiddleware("s3cret")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, query = r.URL.Path, r.URL.RawQuery
+		w.WriteHeader(http.StatusNoContent)
+	})))
+	defer target.Close()
+
+	var out bytes.Buffer
+	l := &listener{out: &out, secret: "s3cret", forward: target.URL + "/app?source=listen"}
+	server := httptest.NewServer(l.handler())
+	defer server.Close()
+
+	resp := postDelivery(t, server.URL+"/hooks/orders?attempt=2", body, signature(body, "s3cret"))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode, out.String())
+	assert.Equal(t, "/app/hooks/orders", path)
+	assert.Equal(t, "source=listen&attempt=2", query)
+}
+
+func TestForwardURL(t *testing.T) {
+	for _, tc := range []struct{ forward, delivery, want string }{
+		{"http://localhost:3000/webhooks", "/", "http://localhost:3000/webhooks"},
+		{"http://localhost:3000", "/orders", "http://localhost:3000/orders"},
+		{"http://localhost:3000/webhooks/", "/orders?x=1", "http://localhost:3000/webhooks/orders?x=1"},
+	} {
+		delivery, err := url.Parse(tc.delivery)
+		require.NoError(t, err)
+		got, err := forwardURL(tc.forward, delivery)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, got)
+	}
+}
+
+func TestListener_Rejects(t *testing.T) {
+	var out bytes.Buffer
+	record := &memoryRecorder{}
+	l := &listener{out: &out, secret: "s3cret", record: record}
+	server := httptest.NewServer(l.handler())
+	defer server.Close()
+
+	resp := postDelivery(t, server.URL, verifyBody+"\n", signature(verifyBody, "s3cret"))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Contains(t, out.String(), "(REJECTED)")
+	assert.Contains(t, out.String(), "a trailing newline was added")
+	assert.Empty(t, record.deliveries)
+}
+
+func TestListen_Command(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deliveries.jsonl")
+	ctx, cancel := context.WithCancel(context.Background())
+	stderrR, stderrW := io.Pipe()
+
+	done := make(chan int)
+	go func() {
+		done <- run(ctx, []string{"listen", "--addr", "127.0.0.1:0", "--secret", "s3cret", "--record", path},
+			strings.NewReader(""), io.Discard, stderrW)
+		stderrW.Close()
+	}()
+
+	line, err := bufio.NewReader(stderrR).ReadString('\n')
+	require.NoError(t, err)
+	url := strings.TrimSpace(strings.TrimPrefix(line, "Listening for deliveries on "))
+	go io.Copy(io.Discard, stderrR)
+
+	resp := postDelivery(t, url, verifyBody, signature(verifyBody, "s3cret"))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cancel()
+	select {
+	case code := <-done:
+		assert.Equal(t, exitOK, code)
+	case <-time.After(5 * time.Second):
+		t.Fatal("listen did not stop")
+	}
+
+	recorded, err := replay.LoadFile(path)
+	require.NoError(t, err)
+	require.Len(t, recorded, 1)
+	assert.Equal(t, verifyBody, string(recorded[0].Body))
+
+	code, _, _ := runCLI(t, "", "listen")
+	assert.Equal(t, exitUsage, code)
+}
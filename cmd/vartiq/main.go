@@ -0,0 +1,202 @@
+// Command vartiq manages Vartiq projects, apps and webhooks and sends webhook
+// messages from the command line.
+//
+//	vartiq [--profile NAME] [--output table|json|yaml] <command> ...
+//
+// The client is configured like vartiq.NewFromConfig: from a profile in
+// ~/.vartiq/config, overridden by VARTIQ_* environment variables.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+// Exit codes. API errors exit with the code of their vartiq.ErrorClass.
+const (
+	exitOK           = 0
+	exitError        = 1
+	exitUsage        = 2
+	exitInvalid      = 3
+	exitUnauthorized = 4
+	exitNotFound     = 5
+	exitConflict     = 6
+	exitRateLimited  = 7
+	exitServer       = 8
+	exitUnavailable  = 9
+	exitCanceled     = 130
+)
+
+var classExitCodes = map[vartiq.ErrorClass]int{
+	vartiq.ErrorClassInvalid:      exitInvalid,
+	vartiq.ErrorClassUnauthorized: exitUnauthorized,
+	vartiq.ErrorClassNotFound:     exitNotFound,
+	vartiq.ErrorClassConflict:     exitConflict,
+	vartiq.ErrorClassRateLimited:  exitRateLimited,
+	vartiq.ErrorClassServer:       exitServer,
+	vartiq.ErrorClassUnavailable:  exitUnavailable,
+	vartiq.ErrorClassCanceled:     exitCanceled,
+}
+
+// errUsage is returned for invalid command lines
+var errUsage = errors.New("usage")
+
+// usageError reports a problem with the command line
+func usageError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, args...))
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// cli holds the state of one invocation
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	profile string
+	output  string
+}
+
+// command runs a subcommand with the arguments that follow its name
+type command struct {
+	usage string
+	run   func(c *cli, ctx context.Context, args []string) error
+}
+
+var commands = map[string]command{
+	"projects":    {"manage projects", (*cli).projects},
+	"apps":        {"manage apps", (*cli).apps},
+	"webhooks":    {"manage webhooks", (*cli).webhooks},
+	"messages":    {"send webhook messages", (*cli).messages},
+	"event-types": {"manage the event type catalog", (*cli).eventTypes},
+	"plan":        {"show the changes needed to match a spec file", (*cli).plan},
+	"apply":       {"change projects, apps and webhooks to match a spec file", (*cli).apply},
+	"listen":      {"receive, verify and forward webhook deliveries locally", (*cli).listen},
+	"verify":      {"check the signature of a captured webhook delivery", (*cli).verify},
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := &cli{stdin: stdin, stdout: stdout, stderr: stderr, output: "table"}
+	fs := c.flagSet("vartiq")
+	fs.Usage = func() { c.usage(fs) }
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if err := c.checkOutput(); err != nil {
+		fmt.Fprintf(stderr, "vartiq: %v\n", err)
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		c.usage(fs)
+		return exitUsage
+	}
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "vartiq: unknown command %q\n", fs.Arg(0))
+		c.usage(fs)
+		return exitUsage
+	}
+	// Commands check each result right after its request, so one recorded
+	// status per invocation is enough to classify failed responses
+	err := cmd.run(c, vartiq.WithResponseStatus(ctx), fs.Args()[1:])
+	if err == nil {
+		return exitOK
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	fmt.Fprintf(stderr, "vartiq: %v\n", err)
+	return exitCode(err)
+}
+
+func exitCode(err error) int {
+	if errors.Is(err, errUsage) {
+		return exitUsage
+	}
+	if code, ok := classExitCodes[vartiq.ClassifyError(err)]; ok {
+		return code
+	}
+	return exitError
+}
+
+func (c *cli) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(c.stderr, "usage: vartiq [flags] <command> [arguments]")
+	fmt.Fprintln(c.stderr, "\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(c.stderr, "  %-12s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintln(c.stderr, "\nflags:")
+	fs.PrintDefaults()
+}
+
+// flagSet creates a flag set that accepts the global flags
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.StringVar(&c.profile, "profile", c.profile, "configuration `profile` to use")
+	fs.StringVar(&c.output, "output", c.output, "output `format`: table, json or yaml")
+	fs.StringVar(&c.output, "o", c.output, "shorthand for --output")
+	return fs
+}
+
+// parse parses flags that may appear before, between or after the
+// positional arguments and checks the number of positional arguments
+func (c *cli) parse(fs *flag.FlagSet, args []string, want ...string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	if err := c.checkOutput(); err != nil {
+		return nil, err
+	}
+	if len(positional) != len(want) {
+		return nil, usageError("%s expects %s", fs.Name(), describeArgs(want))
+	}
+	return positional, nil
+}
+
+func describeArgs(names []string) string {
+	if len(names) == 0 {
+		return "no arguments"
+	}
+	return strings.Join(names, " ")
+}
+
+// client creates an API client for the selected profile
+func (c *cli) client() (*vartiq.Client, error) {
+	return vartiq.NewFromConfig(c.profile)
+}
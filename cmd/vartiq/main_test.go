@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/internal/apitest"
+)
+
+// apiServer serves handler and points the CLI's configuration at it
+func apiServer(t *testing.T, handler http.HandlerFunc) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	t.Setenv("VARTIQ_CONFIG_FILE", filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("VARTIQ_PROFILE", "")
+	t.Setenv("VARTIQ_API_KEY", "test-key")
+	t.Setenv("VARTIQ_API_URL", server.URL)
+}
+
+func runCLI(t *testing.T, stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestProjectsList(t *testing.T) {
+	apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/projects", r.URL.Path)
+		assert.Equal(t, "test-key", r.Header.Get("x-api-key"))
+		writeJSON(w, 200, map[string]interface{}{
+			"success": true,
+			"data": []map[string]interface{}{
+				{"id": "p1", "name": "Shop", "createdAt": "2024-01-02T03:04:05Z"},
+				{"id": "p2", "name": "Blog"},
+			},
+		})
+	})
+
+	code, out, _ := runCLI(t, "", "projects", "list")
+	require.Equal(t, 0, code)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 3)
+	assert.Regexp(t, `^ID\s+NAME\s+DESCRIPTION\s+CREATED$`, lines[0])
+	assert.Regexp(t, `^p1\s+Shop\s+2024-01-02T03:04:05Z$`, lines[1])
+
+	code, out, _ = runCLI(t, "", "-o", "json", "projects", "list")
+	require.Equal(t, 0, code)
+	var projects []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &projects))
+	assert.Equal(t, "Blog", projects[1]["name"])
+
+	code, out, _ = runCLI(t, "", "projects", "list", "--output", "yaml")
+	require.Equal(t, 0, code)
+	assert.Contains(t, out, "- company: \"\"")
+	assert.Contains(t, out, "  name: Shop")
+}
+
+func TestWebhooksCreate(t *testing.T) {
+	apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "app-1", body["appId"])
+		assert.Equal(t, []interface{}{map[string]interface{}{"key": "X-Env", "value": "prod"}}, body["customHeaders"])
+		writeJSON(w, 201, map[string]interface{}{
+			"success": true,
+			"data":    map[string]interface{}{"id": "wh-1", "name": body["name"], "url": body["url"], "app": "app-1"},
+		})
+	})
+
+	code, out, stderr := runCLI(t, "", "webhooks", "create",
+		"--app", "app-1", "--name", "Orders", "--url", "https://example.com/hook", "--header", "X-Env=prod")
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, out, "wh-1")
+	assert.Contains(t, out, "https://example.com/hook")
+}
+
+func TestMessagesSend(t *testing.T) {
+	apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]interface{}{"order": "42"}, body["payload"])
+		writeJSON(w, 201, map[string]interface{}{
+			"success": true,
+			"data": map[string]interface{}{"webhookMessages": []map[string]interface{}{
+				{"id": "msg-1", "payload": `{"order":"42"}`},
+			}},
+		})
+	})
+
+	code, out, stderr := runCLI(t, `{"order":"42"}`, "messages", "send", "--app", "app-1", "--data", "-", "-o", "json")
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, out, `"id": "msg-1"`)
+}
+
+func TestExitCodes(t *testing.T) {
+	status := http.StatusNotFound
+	apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(status)
+			return
+		}
+		writeJSON(w, status, map[string]interface{}{"success": false, "message": "project not found"})
+	})
+
+	code, _, stderr := runCLI(t, "", "projects", "get", "p1")
+	assert.Equal(t, exitNotFound, code)
+	assert.Contains(t, stderr, "project not found")
+
+	status = http.StatusUnauthorized
+	code, _, _ = runCLI(t, "", "projects", "delete", "p1")
+	assert.Equal(t, exitUnauthorized, code)
+
+	status = http.StatusServiceUnavailable
+	code, _, _ = runCLI(t, "", "apps", "list", "--project", "p1")
+	assert.Equal(t, exitServer, code)
+}
+
+func TestUsageErrors(t *testing.T) {
+	apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected API call")
+	})
+
+	for _, args := range [][]string{
+		{},
+		{"nope"},
+		{"projects"},
+		{"projects", "get"},
+		{"projects", "update", "p1"},
+		{"apps", "list"},
+		{"messages", "send", "--app", "a", "--data", "{not json"},
+		{"projects", "list", "--unknown"},
+		{"projects", "delete", "p1", "--dry-run"},
+		{"projects", "list", "-l", "a=b=c"},
+		{"webhooks", "pause", "w1", "--for", "soon"},
+	} {
+		code, _, _ := runCLI(t, "", args...)
+		assert.Equal(t, exitUsage, code, args)
+	}
+
+	code, _, _ := runCLI(t, "", "-o", "xml", "projects", "get", "p1")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestMissingAPIKey(t *testing.T) {
+	apiServer(t, func(w http.ResponseWriter, r *http.Request) {})
+	t.Setenv("VARTIQ_API_KEY", "")
+
+	code, _, stderr := runCLI(t, "", "projects", "list")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "no API key")
+}
+
+func TestProjectsExportImport(t *testing.T) {
+	api := fakeAPI(t)
+	projectID := api.Add(apitest.Projects, apitest.Object{"name": "shop"})
+	appID := api.Add(apitest.Apps, apitest.Object{"name": "orders", "projectId": projectID})
+	api.Add(apitest.Webhooks, apitest.Object{"name": "fulfilment", "url": "https://f.example.com", "app": appID})
+
+	code, out, stderr := runCLI(t, "", "projects", "export", projectID, "--redact")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, out, `"redacted": true`)
+
+	code, out, stderr = runCLI(t, out, "projects", "import", "-")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Regexp(t, `webhook\s+fulfilment\s+webhook-3\s+webhook-\d+`, out)
+	assert.Len(t, api.List(apitest.Projects), 2)
+
+	code, _, _ = runCLI(t, "", "projects", "export", "missing")
+	assert.Equal(t, exitNotFound, code)
+}
+
+func TestProjectsDeleteCascade(t *testing.T) {
+	api := fakeAPI(t)
+	projectID := api.Add(apitest.Projects, apitest.Object{"name": "shop"})
+	appID := api.Add(apitest.Apps, apitest.Object{"name": "orders", "projectId": projectID})
+	api.Add(apitest.Webhooks, apitest.Object{"name": "fulfilment", "url": "https://f.example.com", "app": appID})
+
+	code, out, stderr := runCLI(t, "", "projects", "delete", projectID, "--cascade", "--dry-run")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Regexp(t, `webhook\s+webhook-3\s+fulfilment\s+would delete`, out)
+	assert.Len(t, api.List(apitest.Webhooks), 1)
+
+	api.FailNext("DELETE /webhooks/webhook-3", http.StatusServiceUnavailable)
+	code, out, _ = runCLI(t, "", "projects", "delete", projectID, "--cascade")
+	assert.Equal(t, exitServer, code)
+	assert.Regexp(t, `project\s+project-1\s+shop\s+skipped`, out)
+
+	code, out, stderr = runCLI(t, "", "projects", "delete", projectID, "--cascade")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Regexp(t, `project\s+project-1\s+shop\s+deleted`, out)
+	assert.Empty(t, api.List(apitest.Apps))
+}
+
+func TestAppsClone(t *testing.T) {
+	api := fakeAPI(t)
+	prod := api.Add(apitest.Projects, apitest.Object{"name": "prod"})
+	staging := api.Add(apitest.Projects, apitest.Object{"name": "staging"})
+	appID := api.Add(apitest.Apps, apitest.Object{"name": "orders", "projectId": prod})
+	api.Add(apitest.Webhooks, apitest.Object{"name": "fulfilment", "url": "https://f.example.com", "app": appID})
+
+	code, out, stderr := runCLI(t, "", "apps", "clone", appID, "--project", staging, "--replace-url", "example.com=staging.example.com")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Regexp(t, `webhook-4\s+webhook-6`, out)
+	copied, _ := api.Get(apitest.Webhooks, "webhook-6")
+	assert.Equal(t, "https://f.staging.example.com", copied["url"])
+
+	code, _, _ = runCLI(t, "", "apps", "clone", appID, "--project", staging, "--replace-url", "nope")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestMetadataAndSelector(t *testing.T) {
+	api := fakeAPI(t)
+
+	code, _, stderr := runCLI(t, "", "projects", "create", "--name", "shop", "--metadata", "team=web", "--metadata", "env=prod")
+	require.Equal(t, exitOK, code, stderr)
+	code, _, stderr = runCLI(t, "", "projects", "create", "--name", "search")
+	require.Equal(t, exitOK, code, stderr)
+	project, _ := api.Get(apitest.Projects, "project-1")
+	assert.Equal(t, map[string]interface{}{"team": "web", "env": "prod"}, project["metadata"])
+
+	code, out, stderr := runCLI(t, "", "projects", "list", "-l", "team=web")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, out, "shop")
+	assert.NotContains(t, out, "search")
+
+	code, _, stderr = runCLI(t, "", "projects", "update", "project-2", "--metadata", "team=search")
+	require.Equal(t, exitOK, code, stderr)
+	code, out, _ = runCLI(t, "", "projects", "list", "--selector", "team")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, out, "search")
+}
+
+func TestWebhooksStatus(t *testing.T) {
+	api := fakeAPI(t)
+	id := api.Add(apitest.Webhooks, apitest.Object{"name": "fulfilment", "url": "https://f.example.com"})
+
+	code, out, stderr := runCLI(t, "", "webhooks", "disable", id)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, out, "disabled")
+
+	code, out, stderr = runCLI(t, "", "webhooks", "pause", id, "--until", "2999-01-01T00:00:00Z")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, out, "paused until 2999-01-01T00:00:00Z")
+
+	code, out, stderr = runCLI(t, "", "webhooks", "enable", id)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, out, "active")
+	webhook, _ := api.Get(apitest.Webhooks, id)
+	assert.Equal(t, "active", webhook["status"])
+	assert.Nil(t, webhook["pausedUntil"])
+}
+
+func TestEventTypes(t *testing.T) {
+	api := fakeAPI(t)
+
+	code, out, stderr := runCLI(t, `{"orderId":"42"}`, "event-types", "create", "--name", "order.created", "--example", "-")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, out, "order.created")
+	eventType, _ := api.Get(apitest.EventTypes, "event-type-1")
+	assert.Equal(t, map[string]interface{}{"orderId": "42"}, eventType["examplePayload"])
+
+	code, out, stderr = runCLI(t, "", "event-types", "list")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, out, "event-type-1")
+
+	code, _, stderr = runCLI(t, "", "webhooks", "create", "--app", "app-1", "--name", "orders", "--url", "https://example.com",
+		"--event-type", "order.created", "--event-type", "order.shipped")
+	require.Equal(t, exitOK, code, stderr)
+	webhook, _ := api.Get(apitest.Webhooks, "webhook-2")
+	assert.Equal(t, []interface{}{"order.created", "order.shipped"}, webhook["eventTypes"])
+
+	code, _, stderr = runCLI(t, "", "webhooks", "update", "webhook-2", "--all-events")
+	require.Equal(t, exitOK, code, stderr)
+	webhook, _ = api.Get(apitest.Webhooks, "webhook-2")
+	assert.Empty(t, webhook["eventTypes"])
+
+	code, out, stderr = runCLI(t, "", "messages", "send", "--app", "app-1", "--data", "{}", "--event-type", "order.created")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, out, "order.created")
+
+	code, out, stderr = runCLI(t, "", "event-types", "delete", "event-type-1")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Regexp(t, `ID\s+KIND\s+DELETED\n+event-type-1\s+event type\s+true`, out)
+	assert.Empty(t, api.List(apitest.EventTypes))
+}
+
+func TestWebhooksRetryPolicy(t *testing.T) {
+	api := fakeAPI(t)
+
+	code, _, stderr := runCLI(t, "", "webhooks", "create", "--app", "app-1", "--name", "orders", "--url", "https://example.com",
+		"--max-attempts", "4", "--backoff", "30s,5m", "--attempt-timeout", "15s", "--failure-status", "500,503")
+	require.Equal(t, exitOK, code, stderr)
+	webhook, _ := api.Get(apitest.Webhooks, "webhook-1")
+	assert.Equal(t, map[string]interface{}{
+		"maxAttempts":        float64(4),
+		"backoff":            []interface{}{"30s", "5m0s"},
+		"attemptTimeout":     "15s",
+		"failureStatusCodes": []interface{}{float64(500), float64(503)},
+	}, webhook["retryPolicy"])
+
+	code, _, stderr = runCLI(t, "", "webhooks", "update", "webhook-1", "--default-retries")
+	require.Equal(t, exitOK, code, stderr)
+	webhook, _ = api.Get(apitest.Webhooks, "webhook-1")
+	assert.Nil(t, webhook["retryPolicy"])
+
+	for _, args := range [][]string{
+		{"--max-attempts", "-1"},
+		{"--backoff", "soon"},
+		{"--failure-status", "5xx"},
+		{"--max-attempts", "2", "--backoff", "1s,2s"},
+	} {
+		code, _, _ = runCLI(t, "", append([]string{"webhooks", "update", "webhook-1"}, args...)...)
+		assert.Equal(t, exitUsage, code, args)
+	}
+	assert.Equal(t, 1, api.Count("PUT"))
+}
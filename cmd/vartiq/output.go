@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// table is the tabular form of a command's result
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// print writes v in the selected output format. Table output uses t, JSON
+// and YAML output encode v with the field names of the API.
+func (c *cli) print(v interface{}, t table) error {
+	switch strings.ToLower(c.output) {
+	case "", "table":
+		return writeTable(c.stdout, t)
+	case "json":
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "yaml", "yml":
+		return writeYAML(c.stdout, v)
+	}
+	return c.checkOutput()
+}
+
+// checkOutput validates the output format before any API call is made
+func (c *cli) checkOutput() error {
+	switch strings.ToLower(c.output) {
+	case "", "table", "json", "yaml", "yml":
+		return nil
+	}
+	return usageError("unknown output format %q", c.output)
+}
+
+func writeTable(w io.Writer, t table) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeYAML encodes v through its JSON form, so YAML keys match the API's
+// JSON field names and custom JSON encodings such as vartiq.Time apply
+func writeYAML(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,1016 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+type action func(ctx context.Context, args []string) error
+
+// dispatch runs the action named by the first argument
+func (c *cli) dispatch(ctx context.Context, resource string, args []string, actions map[string]action) error {
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(args) == 0 {
+		return usageError("vartiq %s <%s>", resource, strings.Join(names, "|"))
+	}
+	run, ok := actions[args[0]]
+	if !ok {
+		return usageError("unknown %s command %q, expected one of %s", resource, args[0], strings.Join(names, ", "))
+	}
+	return run(ctx, args[1:])
+}
+
+// deleted reports a successful delete
+func (c *cli) deleted(kind, id string) error {
+	v := map[string]interface{}{"id": id, "kind": kind, "deleted": true}
+	return c.print(v, table{header: []string{"ID", "KIND", "DELETED"}, rows: [][]string{{id, kind, "true"}}})
+}
+
+func cascadeOptions(dryRun bool) []vartiq.CascadeOption {
+	if dryRun {
+		return []vartiq.CascadeOption{vartiq.WithDryRun()}
+	}
+	return nil
+}
+
+// cascaded reports the outcome of a cascading delete. The report is printed
+// even when some deletes failed, so the caller can see what is left.
+func (c *cli) cascaded(report *vartiq.CascadeReport, err error) error {
+	if report == nil || (err != nil && len(report.Deleted)+len(report.Failed) == 0) {
+		return err
+	}
+	status := "deleted"
+	if report.DryRun {
+		status = "would delete"
+	}
+	t := table{header: []string{"KIND", "ID", "NAME", "STATUS"}}
+	for _, r := range report.Deleted {
+		t.rows = append(t.rows, []string{r.Kind, r.ID, r.Name, status})
+	}
+	for _, f := range report.Failed {
+		t.rows = append(t.rows, []string{f.Kind, f.ID, f.Name, "failed: " + f.Error})
+	}
+	for _, r := range report.Skipped {
+		t.rows = append(t.rows, []string{r.Kind, r.ID, r.Name, "skipped"})
+	}
+	if perr := c.print(report, t); perr != nil {
+		return perr
+	}
+	return err
+}
+
+func (c *cli) projects(ctx context.Context, args []string) error {
+	return c.dispatch(ctx, "projects", args, map[string]action{
+		"list": func(ctx context.Context, args []string) error {
+			fs := c.flagSet("projects list")
+			selector := selectorFlag(fs)
+			if _, err := c.parse(fs, args); err != nil {
+				return err
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.Project.List(ctx, selector.option())
+			if err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, resp.Success, resp.Message); err != nil {
+				return err
+			}
+			return c.print(resp.Data, projectTable(resp.Data...))
+		},
+		"get": func(ctx context.Context, args []string) error {
+			pos, err := c.parse(c.flagSet("projects get"), args, "PROJECT_ID")
+			if err != nil {
+				return err
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.Project.Get(ctx, pos[0])
+			if err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, resp.Success, resp.Message); err != nil {
+				return err
+			}
+			return c.print(resp.Data, projectTable(resp.Data))
+		},
+		"create": func(ctx context.Context, args []string) error {
+			fs := c.flagSet("projects create")
+			req := &vartiq.CreateProjectRequest{}
+			fs.StringVar(&req.Name, "name", "", "project name (required)")
+			fs.StringVar(&req.Description, "description", "", "project description")
+			metadata := metadataFlag(fs)
+			if _, err := c.parse(fs, args); err != nil {
+				return err
+			}
+			if req.Name == "" {
+				return usageError("--name is required")
+			}
+			req.Metadata = metadata.value()
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.Project.Create(ctx, req)
+			if err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, resp.Success, resp.Message); err != nil {
+				return err
+			}
+			return c.print(resp.Data, projectTable(resp.Data))
+		},
+		"update": func(ctx context.Context, args []string) error {
+			fs := c.flagSet("projects update")
+			req := &vartiq.UpdateProjectRequest{}
+			fs.StringVar(&req.Name, "name", "", "new project name")
+			fs.StringVar(&req.Description, "description", "", "new project description")
+			metadata := metadataFlag(fs)
+			pos, err := c.parse(fs, args, "PROJECT_ID")
+			if err != nil {
+				return err
+			}
+			req.Metadata = metadata.value()
+			if req.Name == "" && req.Description == "" && req.Metadata == nil {
+				return usageError("nothing to update, set --name, --description or --metadata")
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.Project.Update(ctx, pos[0], req)
+			if err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, resp.Success, resp.Message); err != nil {
+				return err
+			}
+			return c.print(resp.Data, projectTable(resp.Data))
+		},
+		"export": func(ctx context.Context, args []string) error {
+			fs := c.flagSet("projects export")
+			redact := fs.Bool("redact", false, "leave webhook secrets and auth credentials out")
+			pos, err := c.parse(fs, args, "PROJECT_ID")
+			if err != nil {
+				return err
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			var opts []vartiq.ExportOption
+			if *redact {
+				opts = append(opts, vartiq.WithRedactedSecrets())
+			}
+			doc, err := client.Project.Export(ctx, pos[0], opts...)
+			if err != nil {
+				return err
+			}
+			enc := json.NewEncoder(c.stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(doc)
+		},
+		"import": func(ctx context.Context, args []string) error {
+			fs := c.flagSet("projects import")
+			target := fs.String("project", "", "existing project `ID` to import into; by default a new project is created")
+			pos, err := c.parse(fs, args, "FILE")
+			if err != nil {
+				return err
+			}
+			b, err := c.readBody(pos[0])
+			if err != nil {
+				return err
+			}
+			var doc vartiq.ExportDocument
+			if err := json.Unmarshal(b, &doc); err != nil {
+				return fmt.Errorf("invalid export document: %w", err)
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			report, err := client.Project.Import(ctx, &doc, *target)
+			if report != nil {
+				for _, w := range report.Warnings {
+					fmt.Fprintf(c.stderr, "warning: %s\n", w)
+				}
+			}
+			if err != nil {
+				return err
+			}
+			t := table{header: []string{"KIND", "NAME", "OLD ID", "NEW ID"}}
+			for _, r := range report.Created {
+				t.rows = append(t.rows, []string{r.Kind, r.Name, r.OldID, r.NewID})
+			}
+			return c.print(report, t)
+		},
+		"delete": func(ctx context.Context, args []string) error {
+			fs := c.flagSet("projects delete")
+			cascade := fs.Bool("cascade", false, "also delete the project's apps and webhooks")
+			dryRun := fs.Bool("dry-run", false, "with --cascade, list what would be deleted")
+			pos, err := c.parse(fs, args, "PROJECT_ID")
+			if err != nil {
+				return err
+			}
+			if *dryRun && !*cascade {
+				return usageError("--dry-run requires --cascade")
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			if *cascade {
+				report, err := client.Project.DeleteCascade(ctx, pos[0], cascadeOptions(*dryRun)...)
+				return c.cascaded(report, err)
+			}
+			if err := client.Project.Delete(ctx, pos[0]); err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, true, ""); err != nil {
+				return err
+			}
+			return c.deleted("project", pos[0])
+		},
+	})
+}
+
+func (c *cli) apps(ctx context.Context, args []string) error {
+	return c.dispatch(ctx, "apps", args, map[string]action{
+		"list": func(ctx context.Context, args []string) error {
+			fs := c.flagSet("apps list")
+			projectID := fs.String("project", "", "project ID (required)")
+			selector := selectorFlag(fs)
+			if _, err := c.parse(fs, args); err != nil {
+				return err
+			}
+			if *projectID == "" {
+				return usageError("--project is required")
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.App.List(ctx, *projectID, selector.option())
+			if err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, resp.Success, resp.Message); err != nil {
+				return err
+			}
+			return c.print(resp.Data, appTable(resp.Data...))
+		},
+		"get": func(ctx context.Context, args []string) error {
+			pos, err := c.parse(c.flagSet("apps get"), args, "APP_ID")
+			if err != nil {
+				return err
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.App.Get(ctx, pos[0])
+			if err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, resp.Success, resp.Message); err != nil {
+				return err
+			}
+			return c.print(resp.Data, appTable(resp.Data))
+		},
+		"create": func(ctx context.Context, args []string) error {
+			fs := c.flagSet("apps create")
+			req := &vartiq.CreateAppRequest{}
+			fs.StringVar(&req.ProjectID, "project", "", "project ID (required)")
+			fs.StringVar(&req.Name, "name", "", "app name (required)")
+			fs.StringVar(&req.Description, "description", "", "app description")
+			metadata := metadataFlag(fs)
+			if _, err := c.parse(fs, args); err != nil {
+				return err
+			}
+			if req.ProjectID == "" || req.Name == "" {
+				return usageError("--project and --name are required")
+			}
+			req.Metadata = metadata.value()
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.App.Create(ctx, req)
+			if err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, resp.Success, resp.Message); err != nil {
+				return err
+			}
+			return c.print(resp.Data, appTable(resp.Data))
+		},
+		"update": func(ctx context.Context, args []string) error {
+			fs := c.flagSet("apps update")
+			req := &vartiq.UpdateAppRequest{}
+			fs.StringVar(&req.Name, "name", "", "new app name")
+			fs.StringVar(&req.Description, "description", "", "new app description")
+			metadata := metadataFlag(fs)
+			pos, err := c.parse(fs, args, "APP_ID")
+			if err != nil {
+				return err
+			}
+			req.Metadata = metadata.value()
+			if req.Name == "" && req.Description == "" && req.Metadata == nil {
+				return usageError("nothing to update, set --name, --description or --metadata")
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.App.Update(ctx, pos[0], req)
+			if err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, resp.Success, resp.Message); err != nil {
+				return err
+			}
+			return c.print(resp.Data, appTable(resp.Data))
+		},
+		"clone": func(ctx context.Context, args []string) error {
+			fs := c.flagSet("apps clone")
+			projectID := fs.String("project", "", "target project ID (required)")
+			name := fs.String("name", "", "name of the copy; defaults to the source app's name")
+			replace := fs.String("replace-url", "", "rewrite webhook URLs, given as `OLD=NEW`")
+			pos, err := c.parse(fs, args, "APP_ID")
+			if err != nil {
+				return err
+			}
+			if *projectID == "" {
+				return usageError("--project is required")
+			}
+			opts := []vartiq.CloneOption{vartiq.WithCloneName(*name)}
+			if *replace != "" {
+				old, repl, ok := strings.Cut(*replace, "=")
+				if !ok || old == "" {
+					return usageError("invalid --replace-url %q, expected OLD=NEW", *replace)
+				}
+				opts = append(opts, vartiq.WithURLRewrite(func(w vartiq.Webhook) string {
+					return strings.ReplaceAll(w.URL, old, repl)
+				}))
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			result, err := client.App.Clone(ctx, pos[0], *projectID, opts...)
+			if err != nil {
+				return err
+			}
+			t := table{header: []string{"OLD ID", "NEW ID"}}
+			for oldID, newID := range result.IDs {
+				t.rows = append(t.rows, []string{oldID, newID})
+			}
+			sort.Slice(t.rows, func(i, j int) bool { return t.rows[i][0] < t.rows[j][0] })
+			return c.print(result, t)
+		},
+		"delete": func(ctx context.Context, args []string) error {
+			fs := c.flagSet("apps delete")
+			cascade := fs.Bool("cascade", false, "also delete the app's webhooks")
+			dryRun := fs.Bool("dry-run", false, "with --cascade, list what would be deleted")
+			pos, err := c.parse(fs, args, "APP_ID")
+			if err != nil {
+				return err
+			}
+			if *dryRun && !*cascade {
+				return usageError("--dry-run requires --cascade")
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			if *cascade {
+				report, err := client.App.DeleteCascade(ctx, pos[0], cascadeOptions(*dryRun)...)
+				return c.cascaded(report, err)
+			}
+			if err := client.App.Delete(ctx, pos[0]); err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, true, ""); err != nil {
+				return err
+			}
+			return c.deleted("app", pos[0])
+		},
+	})
+}
+
+// headerFlags collects repeated --header key=value flags
+type headerFlags []vartiq.Header
+
+func (h *headerFlags) String() string {
+	parts := make([]string, len(*h))
+	for i, header := range *h {
+		parts[i] = header.Key + "=" + header.Value
+	}
+	return strings.Join(parts, ",")
+}
+
+func (h *headerFlags) Set(v string) error {
+	key, value, ok := strings.Cut(v, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", v)
+	}
+	*h = append(*h, vartiq.Header{Key: key, Value: value})
+	return nil
+}
+
+// retryFlags are the retry policy flags of webhooks create and update
+type retryFlags struct {
+	maxAttempts    int
+	backoff        string
+	attemptTimeout string
+	failureStatus  string
+}
+
+func retryPolicyFlags(fs *flag.FlagSet) *retryFlags {
+	r := &retryFlags{}
+	fs.IntVar(&r.maxAttempts, "max-attempts", 0, "deliver each message at most `n` times, including the first attempt")
+	fs.StringVar(&r.backoff, "backoff", "", "comma-separated `delays` before each retry, e.g. 30s,5m,1h; the last one repeats")
+	fs.StringVar(&r.attemptTimeout, "attempt-timeout", "", "how long each attempt may take, e.g. 15s")
+	fs.StringVar(&r.failureStatus, "failure-status", "", "comma-separated status `codes` that count as failed; by default any status outside 2xx")
+	return r
+}
+
+// policy returns the retry policy set by the flags, or nil if none were given
+func (r *retryFlags) policy() (*vartiq.RetryPolicy, error) {
+	if *r == (retryFlags{}) {
+		return nil, nil
+	}
+	p := &vartiq.RetryPolicy{MaxAttempts: r.maxAttempts}
+	for _, s := range splitList(r.backoff) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, usageError("invalid --backoff delay %q", s)
+		}
+		p.Backoff = append(p.Backoff, vartiq.Duration(d))
+	}
+	if r.attemptTimeout != "" {
+		d, err := time.ParseDuration(r.attemptTimeout)
+		if err != nil {
+			return nil, usageError("invalid --attempt-timeout %q", r.attemptTimeout)
+		}
+		p.AttemptTimeout = vartiq.Duration(d)
+	}
+	for _, s := range splitList(r.failureStatus) {
+		code, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, usageError("invalid --failure-status code %q", s)
+		}
+		p.FailureStatusCodes = append(p.FailureStatusCodes, code)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, usageError("%v", err)
+	}
+	return p, nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// stringList collects the values of a repeated flag
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// metadataFlags collects repeated --metadata key=value flags
+type metadataFlags map[string]string
+
+func metadataFlag(fs *flag.FlagSet) metadataFlags {
+	m := metadataFlags{}
+	fs.Var(m, "metadata", "`key=value` label, may be repeated")
+	return m
+}
+
+func (m metadataFlags) String() string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		keys[i] = k + "=" + m[k]
+	}
+	return strings.Join(keys, ",")
+}
+
+func (m metadataFlags) Set(v string) error {
+	key, value, ok := strings.Cut(v, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", v)
+	}
+	m[key] = value
+	return nil
+}
+
+// value returns the collected labels, or nil if none were given
+func (m metadataFlags) value() map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// selectorFlags is the --selector/-l flag of list commands. Selectors are
+// checked while parsing, so a bad one is a usage error.
+type selectorFlags struct{ vartiq.LabelSelector }
+
+func selectorFlag(fs *flag.FlagSet) *selectorFlags {
+	s := &selectorFlags{}
+	fs.Var(s, "selector", "only list resources whose metadata matches this label `selector`, e.g. team=payments,env!=prod")
+	fs.Var(s, "l", "shorthand for --selector")
+	return s
+}
+
+func (s *selectorFlags) Set(v string) error {
+	selector, err := vartiq.ParseLabelSelector(v)
+	if err != nil {
+		return err
+	}
+	s.LabelSelector = selector
+	return nil
+}
+
+// option returns the list option that applies the selector
+func (s *selectorFlags) option() vartiq.ListOption {
+	return vartiq.WithLabelSelector(s.String())
+}
+
+func (c *cli) webhooks(ctx context.Context, args []string) error {
+	return c.dispatch(ctx, "webhooks", args, map[string]action{
+		"list": func(ctx context.Context, args []string) error {
+			fs := c.flagSet("webhooks list")
+			appID := fs.String("app", "", "app ID (required)")
+			selector := selectorFlag(fs)
+			if _, err := c.parse(fs, args); err != nil {
+				return err
+			}
+			if *appID == "" {
+				return usageError("--app is required")
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.Webhook.GetAll(ctx, *appID, selector.option())
+			if err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, resp.Success, resp.Message); err != nil {
+				return err
+			}
+			return c.print(resp.Data, webhookTable(resp.Data...))
+		},
+		"get": func(ctx context.Context, args []string) error {
+			pos, err := c.parse(c.flagSet("webhooks get"), args, "WEBHOOK_ID")
+			if err != nil {
+				return err
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.Webhook.GetOne(ctx, pos[0])
+			if err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, resp.Success, resp.Message); err != nil {
+				return err
+			}
+			return c.print(resp.Data, webhookTable(resp.Data))
+		},
+		"create": func(ctx context.Context, args []string) error {
+			fs := c.flagSet("webhooks create")
+			req := &vartiq.CreateWebhookRequest{}
+			var headers headerFlags
+			fs.StringVar(&req.AppID, "app", "", "app ID (required)")
+			fs.StringVar(&req.Name, "name", "", "webhook name (required)")
+			fs.StringVar(&req.URL, "url", "", "delivery URL (required)")
+			fs.Var(&headers, "header", "custom `key=value` header sent with deliveries, may be repeated")
+			var eventTypes stringList
+			fs.Var(&eventTypes, "event-type", "only deliver messages of this event `type`, may be repeated; by default all events are delivered")
+			retry := retryPolicyFlags(fs)
+			fs.StringVar(&req.AuthMethod, "auth", "", "authentication `method`: basic, apiKey or hmac")
+			fs.StringVar(&req.UserName, "username", "", "basic auth user name")
+			fs.StringVar(&req.Password, "password", "", "basic auth password")
+			fs.StringVar(&req.APIKey, "api-key", "", "API key sent with deliveries")
+			fs.StringVar(&req.APIKeyHeader, "api-key-header", "", "header carrying --api-key")
+			fs.StringVar(&req.HMACHeader, "hmac-header", "", "header carrying the HMAC signature")
+			fs.StringVar(&req.HMACSecret, "hmac-secret", "", "HMAC signing secret")
+			metadata := metadataFlag(fs)
+			if _, err := c.parse(fs, args); err != nil {
+				return err
+			}
+			if req.AppID == "" || req.Name == "" || req.URL == "" {
+				return usageError("--app, --name and --url are required")
+			}
+			req.CustomHeaders = headers
+			req.EventTypes = eventTypes
+			req.Metadata = metadata.value()
+			policy, err := retry.policy()
+			if err != nil {
+				return err
+			}
+			req.RetryPolicy = policy
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.Webhook.Create(ctx, req)
+			if err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, resp.Success, resp.Message); err != nil {
+				return err
+			}
+			return c.print(resp.Data, webhookTable(resp.Data))
+		},
+		"update": func(ctx context.Context, args []string) error {
+			fs := c.flagSet("webhooks update")
+			name := fs.String("name", "", "new webhook name")
+			url := fs.String("url", "", "new delivery URL")
+			metadata := metadataFlag(fs)
+			var eventTypes stringList
+			fs.Var(&eventTypes, "event-type", "only deliver messages of this event `type`, may be repeated")
+			allEvents := fs.Bool("all-events", false, "deliver messages of every event type")
+			retry := retryPolicyFlags(fs)
+			defaultRetries := fs.Bool("default-retries", false, "restore the default retry policy")
+			pos, err := c.parse(fs, args, "WEBHOOK_ID")
+			if err != nil {
+				return err
+			}
+			req := map[string]interface{}{}
+			if *name != "" {
+				req["name"] = *name
+			}
+			if *url != "" {
+				req["url"] = *url
+			}
+			if m := metadata.value(); m != nil {
+				req["metadata"] = m
+			}
+			switch {
+			case *allEvents && len(eventTypes) > 0:
+				return usageError("set either --event-type or --all-events")
+			case *allEvents:
+				req["eventTypes"] = []string{}
+			case len(eventTypes) > 0:
+				req["eventTypes"] = []string(eventTypes)
+			}
+			policy, err := retry.policy()
+			switch {
+			case err != nil:
+				return err
+			case *defaultRetries && policy != nil:
+				return usageError("set either retry policy flags or --default-retries")
+			case *defaultRetries:
+				req["retryPolicy"] = nil
+			case policy != nil:
+				req["retryPolicy"] = policy
+			}
+			if len(req) == 0 {
+				return usageError("nothing to update, set --name, --url, --metadata, event type or retry flags")
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.Webhook.Update(ctx, pos[0], req)
+			if err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, resp.Success, resp.Message); err != nil {
+				return err
+			}
+			return c.print(resp.Data, webhookTable(resp.Data))
+		},
+		"disable": func(ctx context.Context, args []string) error {
+			return c.webhookStatus(ctx, "disable", args)
+		},
+		"enable": func(ctx context.Context, args []string) error {
+			return c.webhookStatus(ctx, "enable", args)
+		},
+		"pause": func(ctx context.Context, args []string) error {
+			return c.webhookStatus(ctx, "pause", args)
+		},
+		"delete": func(ctx context.Context, args []string) error {
+			pos, err := c.parse(c.flagSet("webhooks delete"), args, "WEBHOOK_ID")
+			if err != nil {
+				return err
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			if err := client.Webhook.Delete(ctx, pos[0]); err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, true, ""); err != nil {
+				return err
+			}
+			return c.deleted("webhook", pos[0])
+		},
+	})
+}
+
+func (c *cli) messages(ctx context.Context, args []string) error {
+	return c.dispatch(ctx, "messages", args, map[string]action{
+		"send": func(ctx context.Context, args []string) error {
+			fs := c.flagSet("messages send")
+			appID := fs.String("app", "", "app ID (required)")
+			data := fs.String("data", "", "JSON payload, @file to read it from a file, or - for stdin (required)")
+			eventType := fs.String("event-type", "", "event type of the message, e.g. order.created")
+			if _, err := c.parse(fs, args); err != nil {
+				return err
+			}
+			if *appID == "" || *data == "" {
+				return usageError("--app and --data are required")
+			}
+			payload, err := c.readPayload(*data)
+			if err != nil {
+				return err
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.WebhookMessage.CreateWithOptions(ctx, *appID, payload, vartiq.WithEventType(*eventType))
+			var sendErr *vartiq.Error
+			if errors.As(err, &sendErr) {
+				return vartiq.CheckResponse(ctx, false, sendErr.Message)
+			}
+			if err != nil {
+				return err
+			}
+			m := resp.Data
+			return c.print(m, table{
+				header: []string{"ID", "APP", "EVENT TYPE", "DELIVERED", "CREATED"},
+				rows:   [][]string{{m.ID, m.AppID, m.EventType, fmt.Sprint(m.IsDelivered), m.CreatedAt.String()}},
+			})
+		},
+	})
+}
+
+func (c *cli) eventTypes(ctx context.Context, args []string) error {
+	return c.dispatch(ctx, "event-types", args, map[string]action{
+		"list": func(ctx context.Context, args []string) error {
+			if _, err := c.parse(c.flagSet("event-types list"), args); err != nil {
+				return err
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.EventType.List(ctx)
+			if err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, resp.Success, resp.Message); err != nil {
+				return err
+			}
+			return c.print(resp.Data, eventTypeTable(resp.Data...))
+		},
+		"get": func(ctx context.Context, args []string) error {
+			pos, err := c.parse(c.flagSet("event-types get"), args, "EVENT_TYPE_ID")
+			if err != nil {
+				return err
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.EventType.Get(ctx, pos[0])
+			if err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, resp.Success, resp.Message); err != nil {
+				return err
+			}
+			return c.print(resp.Data, eventTypeTable(resp.Data))
+		},
+		"create": func(ctx context.Context, args []string) error {
+			fs := c.flagSet("event-types create")
+			req := &vartiq.CreateEventTypeRequest{}
+			fs.StringVar(&req.Name, "name", "", "event type name, e.g. order.created (required)")
+			fs.StringVar(&req.Description, "description", "", "event type description")
+			example := fs.String("example", "", "example JSON payload, @file or - for stdin")
+			if _, err := c.parse(fs, args); err != nil {
+				return err
+			}
+			if req.Name == "" {
+				return usageError("--name is required")
+			}
+			if *example != "" {
+				payload, err := c.readPayload(*example)
+				if err != nil {
+					return err
+				}
+				req.ExamplePayload = payload
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.EventType.Create(ctx, req)
+			if err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, resp.Success, resp.Message); err != nil {
+				return err
+			}
+			return c.print(resp.Data, eventTypeTable(resp.Data))
+		},
+		"update": func(ctx context.Context, args []string) error {
+			fs := c.flagSet("event-types update")
+			req := &vartiq.UpdateEventTypeRequest{}
+			fs.StringVar(&req.Description, "description", "", "new event type description")
+			example := fs.String("example", "", "new example JSON payload, @file or - for stdin")
+			pos, err := c.parse(fs, args, "EVENT_TYPE_ID")
+			if err != nil {
+				return err
+			}
+			if req.Description == "" && *example == "" {
+				return usageError("nothing to update, set --description or --example")
+			}
+			if *example != "" {
+				payload, err := c.readPayload(*example)
+				if err != nil {
+					return err
+				}
+				req.ExamplePayload = payload
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.EventType.Update(ctx, pos[0], req)
+			if err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, resp.Success, resp.Message); err != nil {
+				return err
+			}
+			return c.print(resp.Data, eventTypeTable(resp.Data))
+		},
+		"delete": func(ctx context.Context, args []string) error {
+			pos, err := c.parse(c.flagSet("event-types delete"), args, "EVENT_TYPE_ID")
+			if err != nil {
+				return err
+			}
+			client, err := c.client()
+			if err != nil {
+				return err
+			}
+			if err := client.EventType.Delete(ctx, pos[0]); err != nil {
+				return err
+			}
+			if err := vartiq.CheckResponse(ctx, true, ""); err != nil {
+				return err
+			}
+			return c.deleted("event type", pos[0])
+		},
+	})
+}
+
+// readPayload reads a JSON payload given inline, as @file or as - for stdin
+func (c *cli) readPayload(data string) (json.RawMessage, error) {
+	var b []byte
+	var err error
+	switch {
+	case data == "-":
+		b, err = io.ReadAll(c.stdin)
+	case strings.HasPrefix(data, "@"):
+		b, err = os.ReadFile(data[1:])
+	default:
+		b = []byte(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payload: %w", err)
+	}
+	if !json.Valid(b) {
+		return nil, usageError("payload is not valid JSON")
+	}
+	return json.RawMessage(b), nil
+}
+
+func eventTypeTable(eventTypes ...vartiq.EventType) table {
+	t := table{header: []string{"ID", "NAME", "DESCRIPTION", "CREATED"}}
+	for _, e := range eventTypes {
+		t.rows = append(t.rows, []string{e.ID, e.Name, e.Description, e.CreatedAt.String()})
+	}
+	return t
+}
+
+func projectTable(projects ...vartiq.Project) table {
+	t := table{header: []string{"ID", "NAME", "DESCRIPTION", "CREATED"}}
+	for _, p := range projects {
+		t.rows = append(t.rows, []string{p.ID, p.Name, p.Description, p.CreatedAt.String()})
+	}
+	return t
+}
+
+func appTable(apps ...vartiq.App) table {
+	t := table{header: []string{"ID", "NAME", "DESCRIPTION", "CREATED"}}
+	for _, a := range apps {
+		t.rows = append(t.rows, []string{a.ID, a.Name, a.Description, a.CreatedAt.String()})
+	}
+	return t
+}
+
+func webhookTable(webhooks ...vartiq.Webhook) table {
+	t := table{header: []string{"ID", "NAME", "URL", "APP", "STATUS", "CREATED"}}
+	now := time.Now()
+	for _, w := range webhooks {
+		status := string(w.StatusAt(now))
+		if status == string(vartiq.WebhookStatusPaused) && !w.PausedUntil.IsZero() {
+			status += " until " + w.PausedUntil.String()
+		}
+		t.rows = append(t.rows, []string{w.ID, w.Name, w.URL, w.AppID, status, w.CreatedAt.String()})
+	}
+	return t
+}
+
+// webhookStatus runs webhooks disable, enable and pause
+func (c *cli) webhookStatus(ctx context.Context, name string, args []string) error {
+	fs := c.flagSet("webhooks " + name)
+	var until, pauseFor *string
+	if name == "pause" {
+		until = fs.String("until", "", "resume at this RFC 3339 `time`; by default the webhook stays paused until enabled")
+		pauseFor = fs.String("for", "", "resume after this `duration`, e.g. 30m")
+	}
+	pos, err := c.parse(fs, args, "WEBHOOK_ID")
+	if err != nil {
+		return err
+	}
+	var resume time.Time
+	if name == "pause" {
+		switch {
+		case *until != "" && *pauseFor != "":
+			return usageError("set either --until or --for")
+		case *until != "":
+			if resume, err = time.Parse(time.RFC3339, *until); err != nil {
+				return usageError("invalid --until %q, expected an RFC 3339 time", *until)
+			}
+		case *pauseFor != "":
+			d, err := time.ParseDuration(*pauseFor)
+			if err != nil || d <= 0 {
+				return usageError("invalid --for %q, expected a positive duration", *pauseFor)
+			}
+			resume = time.Now().Add(d)
+		}
+	}
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	var resp *vartiq.WebhookResponse
+	switch name {
+	case "disable":
+		resp, err = client.Webhook.Disable(ctx, pos[0])
+	case "enable":
+		resp, err = client.Webhook.Enable(ctx, pos[0])
+	default:
+		resp, err = client.Webhook.Pause(ctx, pos[0], resume)
+	}
+	if err != nil {
+		return err
+	}
+	if err := vartiq.CheckResponse(ctx, resp.Success, resp.Message); err != nil {
+		return err
+	}
+	return c.print(resp.Data, webhookTable(resp.Data))
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq/declarative"
+)
+
+// errNotApplied is returned when the user declines to apply a plan
+var errNotApplied = errors.New("apply cancelled")
+
+// diff loads a spec and plans the changes needed to reach it
+func (c *cli) diff(ctx context.Context, name string, args []string) (*declarative.Plan, *specFlags, error) {
+	fs := c.flagSet(name)
+	v := &specFlags{}
+	fs.StringVar(&v.file, "f", "", "spec `file` in YAML or JSON (required)")
+	fs.BoolVar(&v.prune, "prune", false, "delete apps and webhooks of the spec's projects that are not in the spec")
+	if name == "apply" {
+		fs.BoolVar(&v.yes, "yes", false, "apply without asking for confirmation")
+	}
+	if _, err := c.parse(fs, args); err != nil {
+		return nil, nil, err
+	}
+	if v.file == "" {
+		return nil, nil, usageError("-f is required")
+	}
+
+	spec, err := declarative.LoadSpec(v.file)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := c.client()
+	if err != nil {
+		return nil, nil, err
+	}
+	var opts []declarative.PlanOption
+	if v.prune {
+		opts = append(opts, declarative.WithPrune())
+	}
+	plan, err := declarative.Diff(ctx, client, spec, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plan, v, nil
+}
+
+type specFlags struct {
+	file  string
+	prune bool
+	yes   bool
+}
+
+func (c *cli) plan(ctx context.Context, args []string) error {
+	plan, _, err := c.diff(ctx, "plan", args)
+	if err != nil {
+		return err
+	}
+	_, err = plan.WriteTo(c.stdout)
+	return err
+}
+
+func (c *cli) apply(ctx context.Context, args []string) error {
+	plan, v, err := c.diff(ctx, "apply", args)
+	if err != nil {
+		return err
+	}
+	if _, err := plan.WriteTo(c.stdout); err != nil {
+		return err
+	}
+	if plan.Empty() {
+		return nil
+	}
+
+	if !v.yes {
+		fmt.Fprint(c.stdout, "\nApply these changes? Only 'yes' will be accepted: ")
+		answer, _ := bufio.NewReader(c.stdin).ReadString('\n')
+		if strings.TrimSpace(answer) != "yes" {
+			return errNotApplied
+		}
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	applied, err := declarative.Apply(ctx, client, plan)
+	for _, change := range applied {
+		fmt.Fprintf(c.stdout, "%s: done (%s)\n", change, change.ID)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "Applied %d changes.\n", len(applied))
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/internal/apitest"
+)
+
+const syncSpec = `
+projects:
+  - name: shop
+    apps:
+      - name: orders
+        webhooks:
+          - name: fulfilment
+            url: https://fulfilment.example.com
+`
+
+func fakeAPI(t *testing.T) *apitest.Server {
+	api := apitest.New()
+	t.Cleanup(api.Close)
+	t.Setenv("VARTIQ_CONFIG_FILE", filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("VARTIQ_PROFILE", "")
+	t.Setenv("VARTIQ_API_KEY", "test-key")
+	t.Setenv("VARTIQ_API_URL", api.URL)
+	return api
+}
+
+func TestPlanAndApply(t *testing.T) {
+	api := fakeAPI(t)
+	spec := filepath.Join(t.TempDir(), "spec.yaml")
+	require.NoError(t, os.WriteFile(spec, []byte(syncSpec), 0o600))
+
+	code, out, stderr := runCLI(t, "", "plan", "-f", spec)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "+ project shop\n+ app shop/orders\n+ webhook shop/orders/fulfilment\nPlan: 3 to create, 0 to update, 0 to delete.\n", out)
+
+	code, out, _ = runCLI(t, "no\n", "apply", "-f", spec)
+	assert.Equal(t, exitError, code)
+	assert.Empty(t, api.List(apitest.Projects))
+
+	code, out, stderr = runCLI(t, "yes\n", "apply", "-f", spec)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, out, "Applied 3 changes.")
+	assert.Len(t, api.List(apitest.Webhooks), 1)
+
+	code, out, _ = runCLI(t, "", "apply", "-f", spec, "--prune", "--yes")
+	require.Equal(t, exitOK, code)
+	assert.Equal(t, "No changes. Live state matches the spec.\n", out)
+
+	code, _, _ = runCLI(t, "", "plan")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestApplyExitCode(t *testing.T) {
+	api := fakeAPI(t)
+	spec := filepath.Join(t.TempDir(), "spec.yaml")
+	require.NoError(t, os.WriteFile(spec, []byte(syncSpec), 0o600))
+
+	api.FailNext("POST /apps", http.StatusConflict)
+	code, _, stderr := runCLI(t, "", "apply", "-f", spec, "--yes")
+	assert.Equal(t, exitConflict, code, stderr)
+
+	api.FailNext("GET /projects", http.StatusServiceUnavailable)
+	code, _, _ = runCLI(t, "", "plan", "-f", spec)
+	assert.Equal(t, exitServer, code)
+}
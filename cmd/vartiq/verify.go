@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+// errVerificationFailed is returned by verify when the signature is invalid
+var errVerificationFailed = errors.New("verification failed")
+
+// verification is the result of vartiq verify
+type verification struct {
+	Valid             bool     `json:"valid"`
+	Reason            string   `json:"reason,omitempty"`
+	Hints             []string `json:"hints,omitempty"`
+	ExpectedSignature string   `json:"expectedSignature,omitempty"`
+}
+
+func (c *cli) verify(ctx context.Context, args []string) error {
+	fs := c.flagSet("verify")
+	bodyPath := fs.String("body", "-", "`file` holding the raw request body, - for stdin")
+	signature := fs.String("signature", "", "value of the signature header (required)")
+	secret := fs.String("secret", "", "webhook secret")
+	webhookID := fs.String("webhook", "", "webhook `ID` to fetch the secret from instead of --secret")
+	timestamp := fs.String("timestamp", "", "delivery time, as Unix seconds or RFC 3339, to check for clock skew")
+	tolerance := fs.Duration("tolerance", 5*time.Minute, "allowed difference between --timestamp and now")
+	if _, err := c.parse(fs, args); err != nil {
+		return err
+	}
+	if (*secret == "") == (*webhookID == "") {
+		return usageError("set exactly one of --secret and --webhook")
+	}
+
+	body, err := c.readBody(*bodyPath)
+	if err != nil {
+		return err
+	}
+
+	if *webhookID != "" {
+		if *secret, err = c.webhookSecret(ctx, *webhookID); err != nil {
+			return err
+		}
+	}
+
+	var v verification
+	if _, err := vartiq.NewWithOptions("").Verify(body, *signature, *secret); err == nil {
+		v.Valid = true
+	} else {
+		v = diagnose(body, *signature, *secret)
+	}
+	if *timestamp != "" {
+		if err := checkSkew(&v, *timestamp, *tolerance, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	if err := c.printVerification(v); err != nil {
+		return err
+	}
+	if !v.Valid {
+		return errVerificationFailed
+	}
+	return nil
+}
+
+// webhookSecret fetches the signing secret of a webhook
+func (c *cli) webhookSecret(ctx context.Context, webhookID string) (string, error) {
+	client, err := c.client()
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Webhook.GetOne(ctx, webhookID)
+	if err != nil {
+		return "", err
+	}
+	if err := vartiq.CheckResponse(ctx, resp.Success, resp.Message); err != nil {
+		return "", err
+	}
+	if resp.Data.Secret == "" {
+		return "", fmt.Errorf("webhook %s has no signing secret", webhookID)
+	}
+	return resp.Data.Secret, nil
+}
+
+func (c *cli) readBody(path string) ([]byte, error) {
+	var b []byte
+	var err error
+	if path == "-" {
+		b, err = io.ReadAll(c.stdin)
+	} else {
+		b, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	return b, nil
+}
+
+func (c *cli) printVerification(v verification) error {
+	if strings.ToLower(c.output) != "table" && c.output != "" {
+		return c.print(v, table{})
+	}
+	if v.Valid {
+		fmt.Fprintln(c.stdout, "Signature is valid.")
+		return nil
+	}
+	fmt.Fprintln(c.stdout, "Verification failed:")
+	fmt.Fprintf(c.stdout, "  %s\n", v.Reason)
+	for _, hint := range v.Hints {
+		fmt.Fprintf(c.stdout, "  - %s\n", hint)
+	}
+	if v.ExpectedSignature != "" {
+		fmt.Fprintf(c.stdout, "  expected signature for this body and secret: %s\n", v.ExpectedSignature)
+	}
+	return nil
+}
+
+func sign(body []byte, secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// diagnose explains why signature does not verify body with secret
+func diagnose(body []byte, signature, secret string) verification {
+	v := verification{ExpectedSignature: hex.EncodeToString(sign(body, secret))}
+	trimmed := strings.TrimSpace(signature)
+
+	if trimmed == "" {
+		v.Reason = "the signature header is empty"
+		v.Hints = append(v.Hints, "pass the value of the x-vartiq-signature header with --signature")
+		return v
+	}
+	if trimmed != signature {
+		v.Hints = append(v.Hints, "the signature has leading or trailing whitespace; Verify does not trim it")
+	}
+	if prefix, rest, ok := strings.Cut(trimmed, "="); ok && rest != "" && strings.Trim(prefix, "0123456789abcdefABCDEF") != "" {
+		v.Hints = append(v.Hints, fmt.Sprintf("the signature starts with %q; pass only the hex digest", prefix+"="))
+		trimmed = rest
+	}
+
+	received, err := hex.DecodeString(trimmed)
+	if err != nil {
+		v.Reason = "the signature is not valid hex: " + describeHexError(trimmed, err)
+		if b, err := base64.StdEncoding.DecodeString(trimmed); err == nil && len(b) == sha256.Size {
+			v.Hints = append(v.Hints, "the signature looks base64 encoded; Vartiq signatures are hex encoded")
+		}
+		return v
+	}
+	if len(received) != sha256.Size {
+		v.Reason = fmt.Sprintf("the signature is %d bytes long, an HMAC-SHA256 is %d bytes; it may have been truncated", len(received), sha256.Size)
+		return v
+	}
+
+	if hmac.Equal(received, sign(body, secret)) {
+		v.Reason = "the signature is correct, but the header value is not exactly the hex digest"
+		return v
+	}
+	for _, variant := range bodyVariants(body) {
+		if hmac.Equal(received, sign(variant.body, secret)) {
+			v.Reason = "the body differs from the one that was signed: " + variant.difference
+			v.Hints = append(v.Hints, "verify the raw request body before any parsing or re-encoding")
+			return v
+		}
+	}
+	if s := strings.TrimSpace(secret); s != secret && hmac.Equal(received, sign(body, s)) {
+		v.Reason = "the secret has leading or trailing whitespace"
+		return v
+	}
+
+	v.Reason = "the signature was not made with this secret and body"
+	v.Hints = append(v.Hints,
+		"check that the secret belongs to the webhook that received the delivery",
+		"secrets change when a webhook is recreated")
+	return v
+}
+
+func describeHexError(s string, err error) string {
+	var invalid hex.InvalidByteError
+	if errors.As(err, &invalid) {
+		i := strings.IndexByte(s, byte(invalid))
+		return fmt.Sprintf("invalid character %q at position %d", rune(invalid), i)
+	}
+	if errors.Is(err, hex.ErrLength) {
+		return fmt.Sprintf("odd number of digits (%d)", len(s))
+	}
+	return err.Error()
+}
+
+type bodyVariant struct {
+	difference string
+	body       []byte
+}
+
+// bodyVariants returns common accidental changes to a body, described
+// relative to the body that was signed
+func bodyVariants(body []byte) []bodyVariant {
+	variants := []bodyVariant{
+		{"a trailing newline was added", bytes.TrimRight(body, "\r\n")},
+		{"a trailing newline was removed", append(append([]byte(nil), body...), '\n')},
+		{"line endings were converted to CRLF", bytes.ReplaceAll(body, []byte("\r\n"), []byte("\n"))},
+		{"surrounding whitespace was added", bytes.TrimSpace(body)},
+	}
+	var compact bytes.Buffer
+	if json.Compact(&compact, body) == nil {
+		variants = append(variants, bodyVariant{"the JSON was re-indented or pretty-printed", compact.Bytes()})
+		var indented bytes.Buffer
+		if json.Indent(&indented, body, "", "  ") == nil {
+			variants = append(variants, bodyVariant{"the JSON was compacted", indented.Bytes()})
+		}
+	}
+
+	out := variants[:0]
+	for _, variant := range variants {
+		if !bytes.Equal(variant.body, body) {
+			out = append(out, variant)
+		}
+	}
+	return out
+}
+
+// checkSkew fails deliveries whose timestamp is further than tolerance from
+// now
+func checkSkew(v *verification, timestamp string, tolerance time.Duration, now time.Time) error {
+	ts, err := vartiq.ParseTime(timestamp)
+	if err != nil || ts.IsZero() {
+		return usageError("invalid --timestamp %q", timestamp)
+	}
+	skew := now.Sub(ts.Time)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew <= tolerance {
+		return nil
+	}
+	direction := "old"
+	if ts.After(now) {
+		direction = "in the future"
+	}
+	reason := fmt.Sprintf("the delivery timestamp is %s %s, beyond the %s tolerance", skew.Round(time.Second), direction, tolerance)
+	if v.Valid {
+		v.Valid = false
+		v.Reason = "the signature matches, but " + reason
+		v.Hints = append(v.Hints, "receivers that reject stale deliveries will refuse it; check the clocks of sender and receiver")
+		return nil
+	}
+	v.Hints = append(v.Hints, reason)
+	return nil
+}
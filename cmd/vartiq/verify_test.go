@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const verifyBody = `{"event":"order.created","id":42}`
+
+func signature(body, secret string) string {
+	return hex.EncodeToString(sign([]byte(body), secret))
+}
+
+func TestVerify_Valid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "body.json")
+	require.NoError(t, os.WriteFile(path, []byte(verifyBody), 0o600))
+
+	code, out, _ := runCLI(t, "", "verify", "--body", path, "--signature", signature(verifyBody, "s3cret"), "--secret", "s3cret")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "Signature is valid.\n", out)
+
+	code, _, _ = runCLI(t, verifyBody, "verify", "--signature", signature(verifyBody, "s3cret"), "--secret", "s3cret")
+	assert.Equal(t, exitOK, code)
+}
+
+func TestVerify_WebhookSecret(t *testing.T) {
+	apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/webhooks/wh-1", r.URL.Path)
+		writeJSON(w, 200, map[string]interface{}{"success": true, "data": map[string]interface{}{"id": "wh-1", "secret": "from-api"}})
+	})
+
+	code, _, stderr := runCLI(t, verifyBody, "verify", "--webhook", "wh-1", "--signature", signature(verifyBody, "from-api"))
+	assert.Equal(t, exitOK, code, stderr)
+}
+
+func TestVerify_Failures(t *testing.T) {
+	sig := signature(verifyBody, "s3cret")
+	pretty := "{\n  \"event\": \"order.created\",\n  \"id\": 42\n}"
+
+	tests := []struct {
+		name      string
+		body      string
+		signature string
+		secret    string
+		reason    string
+	}{
+		{"empty", verifyBody, "", "s3cret", "the signature header is empty"},
+		{"bad hex", verifyBody, "zz" + sig[2:], "s3cret", `invalid character 'z' at position 0`},
+		{"odd length", verifyBody, sig[1:], "s3cret", "odd number of digits (63)"},
+		{"truncated", verifyBody, sig[:32], "s3cret", "16 bytes long"},
+		{"trailing newline", verifyBody + "\n", sig, "s3cret", "a trailing newline was added"},
+		{"pretty printed", pretty, sig, "s3cret", "the JSON was re-indented or pretty-printed"},
+		{"secret whitespace", verifyBody, sig, "s3cret\n", "the secret has leading or trailing whitespace"},
+		{"wrong secret", verifyBody, sig, "other", "not made with this secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := diagnose([]byte(tt.body), tt.signature, tt.secret)
+			assert.False(t, v.Valid)
+			assert.Contains(t, v.Reason, tt.reason)
+		})
+	}
+
+	v := diagnose([]byte(verifyBody), base64.StdEncoding.EncodeToString(sign([]byte(verifyBody), "s3cret")), "s3cret")
+	assert.Contains(t, v.Hints, "the signature looks base64 encoded; Vartiq signatures are hex encoded")
+
+	v = diagnose([]byte(verifyBody), " sha256="+sig, "s3cret")
+	assert.Contains(t, v.Reason, "not exactly the hex digest")
+	assert.Contains(t, v.Hints, `the signature starts with "sha256="; pass only the hex digest`)
+}
+
+func TestVerify_ExitCodeAndJSON(t *testing.T) {
+	code, out, _ := runCLI(t, verifyBody, "verify", "--signature", signature(verifyBody, "other"), "--secret", "s3cret", "-o", "json")
+	assert.Equal(t, exitError, code)
+	var v verification
+	require.NoError(t, json.Unmarshal([]byte(out), &v))
+	assert.False(t, v.Valid)
+	assert.Equal(t, signature(verifyBody, "s3cret"), v.ExpectedSignature)
+
+	code, _, _ = runCLI(t, verifyBody, "verify", "--signature", "ab")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestVerify_TimestampSkew(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	v := verification{Valid: true}
+	require.NoError(t, checkSkew(&v, "1700000060", 5*time.Minute, now))
+	assert.True(t, v.Valid)
+
+	require.NoError(t, checkSkew(&v, "2023-11-14T22:13:20Z", 5*time.Minute, now.Add(time.Hour)))
+	assert.False(t, v.Valid)
+	assert.Contains(t, v.Reason, "1h0m0s old")
+
+	v = verification{Valid: true}
+	require.NoError(t, checkSkew(&v, "1700000600", 5*time.Minute, now))
+	assert.Contains(t, v.Reason, "10m0s in the future")
+
+	assert.Error(t, checkSkew(&v, "yesterday", time.Minute, now))
+}
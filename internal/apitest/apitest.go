@@ -0,0 +1,258 @@
+// Package apitest provides an in-memory fake of the Vartiq API for tests.
+// It does not import the SDK, so tests of package vartiq can use it too.
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// Resource kinds, named after their API paths
+const (
+	Projects   = "projects"
+	Apps       = "apps"
+	Webhooks   = "webhooks"
+	EventTypes = "event-types"
+)
+
+// Object is a resource as the API returns it
+type Object map[string]interface{}
+
+// parentKeys are the fields that link resources to their parent, and the
+// query parameter used to list them
+var parentKeys = map[string]struct{ field, query string }{
+	Apps:     {"projectId", "projectId"},
+	Webhooks: {"app", "appId"},
+}
+
+// Server is a fake Vartiq API backed by maps. Resources keep every field sent
+// on create or update, so the fake follows new fields without changes.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	nextID   int
+	objects  map[string]map[string]Object
+	order    map[string][]string
+	requests []string
+	fail     map[string]int
+}
+
+// New starts a fake API server. Close it when done.
+func New() *Server {
+	s := &Server{
+		objects: map[string]map[string]Object{Projects: {}, Apps: {}, Webhooks: {}, EventTypes: {}},
+		order:   make(map[string][]string),
+		fail:    make(map[string]int),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Add stores a resource and returns its ID
+func (s *Server) Add(kind string, obj Object) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.add(kind, obj)
+}
+
+func (s *Server) add(kind string, obj Object) string {
+	s.nextID++
+	id := fmt.Sprintf("%s-%d", strings.TrimSuffix(kind, "s"), s.nextID)
+	stored := Object{"id": id, "createdAt": "2024-01-01T00:00:00Z", "updatedAt": "2024-01-01T00:00:00Z"}
+	for k, v := range obj {
+		stored[k] = v
+	}
+	if kind == Webhooks {
+		if _, ok := stored["secret"]; !ok {
+			stored["secret"] = "whsec-" + id
+		}
+	}
+	s.objects[kind][id] = stored
+	s.order[kind] = append(s.order[kind], id)
+	return id
+}
+
+// Get returns a copy of a stored resource
+func (s *Server) Get(kind, id string) (Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.objects[kind][id]
+	return copyObject(obj), ok
+}
+
+// List returns copies of the resources of a kind in creation order
+func (s *Server) List(kind string) []Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.list(kind, "", "")
+}
+
+func (s *Server) list(kind, field, value string) []Object {
+	out := []Object{}
+	for _, id := range s.order[kind] {
+		obj, ok := s.objects[kind][id]
+		if !ok {
+			continue
+		}
+		if field != "" && obj[field] != value {
+			continue
+		}
+		out = append(out, copyObject(obj))
+	}
+	return out
+}
+
+// Requests returns the requests served so far as "METHOD /path"
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Count returns how many requests with the given method were served
+func (s *Server) Count(method string) int {
+	n := 0
+	for _, r := range s.Requests() {
+		if strings.HasPrefix(r, method+" ") {
+			n++
+		}
+	}
+	return n
+}
+
+// FailNext makes the next request for "METHOD /path" answer with status
+func (s *Server) FailNext(request string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fail[request] = status
+}
+
+func copyObject(obj Object) Object {
+	if obj == nil {
+		return nil
+	}
+	out := make(Object, len(obj))
+	for k, v := range obj {
+		out[k] = v
+	}
+	return out
+}
+
+func reply(w http.ResponseWriter, status int, data interface{}, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": status < 400,
+		"message": message,
+		"data":    data,
+	})
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	request := r.Method + " " + r.URL.Path
+	s.requests = append(s.requests, request)
+	if status, ok := s.fail[request]; ok {
+		delete(s.fail, request)
+		reply(w, status, nil, http.StatusText(status))
+		return
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	kind := segments[0]
+	if kind == "webhook-messages" && r.Method == http.MethodPost {
+		s.createMessage(w, r)
+		return
+	}
+	if _, ok := s.objects[kind]; !ok || len(segments) > 2 {
+		reply(w, http.StatusNotFound, nil, "route not found")
+		return
+	}
+
+	var body Object
+	if r.Method == http.MethodPost || r.Method == http.MethodPut {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			reply(w, http.StatusBadRequest, nil, "invalid JSON body")
+			return
+		}
+	}
+
+	if len(segments) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			parent := parentKeys[kind]
+			value := r.URL.Query().Get(parent.query)
+			field := ""
+			if value != "" {
+				field = parent.field
+			}
+			reply(w, http.StatusOK, s.list(kind, field, value), "")
+		case http.MethodPost:
+			if kind == Webhooks {
+				body["app"] = body["appId"]
+				delete(body, "appId")
+			}
+			id := s.add(kind, body)
+			reply(w, http.StatusCreated, s.objects[kind][id], "created")
+		default:
+			reply(w, http.StatusMethodNotAllowed, nil, "method not allowed")
+		}
+		return
+	}
+
+	id := segments[1]
+	obj, ok := s.objects[kind][id]
+	if !ok {
+		reply(w, http.StatusNotFound, nil, strings.TrimSuffix(kind, "s")+" not found")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		reply(w, http.StatusOK, obj, "")
+	case http.MethodPut:
+		for k, v := range body {
+			obj[k] = v
+		}
+		obj["updatedAt"] = "2024-01-02T00:00:00Z"
+		reply(w, http.StatusOK, obj, "updated")
+	case http.MethodDelete:
+		delete(s.objects[kind], id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		reply(w, http.StatusMethodNotAllowed, nil, "method not allowed")
+	}
+}
+
+// Messages returns the webhook messages created so far
+func (s *Server) Messages() []Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.list("messages", "", "")
+}
+
+func (s *Server) createMessage(w http.ResponseWriter, r *http.Request) {
+	var body Object
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		reply(w, http.StatusBadRequest, nil, "invalid JSON body")
+		return
+	}
+	if s.objects["messages"] == nil {
+		s.objects["messages"] = map[string]Object{}
+	}
+	payload, _ := json.Marshal(body["payload"])
+	msg := Object{"app": body["appId"], "payload": string(payload)}
+	for k, v := range body {
+		if k != "appId" && k != "payload" {
+			msg[k] = v
+		}
+	}
+	id := s.add("messages", msg)
+	reply(w, http.StatusCreated, map[string]interface{}{"webhookMessages": []Object{s.objects["messages"][id]}}, "created")
+}
@@ -2,6 +2,8 @@ package vartiq
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 )
 
 type AppService struct {
@@ -9,18 +11,26 @@ type AppService struct {
 }
 
 type App struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Company     string `json:"company"`
-	CreatedAt   string `json:"createdAt"`
-	UpdatedAt   string `json:"updatedAt"`
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Company     string            `json:"company"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	CreatedAt   Time              `json:"createdAt"`
+	UpdatedAt   Time              `json:"updatedAt"`
 }
 
+// Created returns when the app was created
+func (a App) Created() time.Time { return a.CreatedAt.Time }
+
+// Updated returns when the app was last updated
+func (a App) Updated() time.Time { return a.UpdatedAt.Time }
+
 type CreateAppRequest struct {
-	Name        string `json:"name"`
-	ProjectID   string `json:"projectId"`
-	Description string `json:"description,omitempty"`
+	Name        string            `json:"name"`
+	ProjectID   string            `json:"projectId"`
+	Description string            `json:"description,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
 }
 
 type CreateAppResponse struct {
@@ -31,8 +41,7 @@ type CreateAppResponse struct {
 
 func (s *AppService) Create(ctx context.Context, req *CreateAppRequest) (*CreateAppResponse, error) {
 	resp := &CreateAppResponse{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+	_, err := s.client.request(ctx, "app.create").
 		SetBody(req).
 		SetResult(resp).
 		Post("/apps")
@@ -42,8 +51,9 @@ func (s *AppService) Create(ctx context.Context, req *CreateAppRequest) (*Create
 	return resp, nil
 }
 
-// List all apps for a project
-func (s *AppService) List(ctx context.Context, projectID string) (*struct {
+// List all apps for a project, or those matching the label selector given
+// in opts
+func (s *AppService) List(ctx context.Context, projectID string, opts ...ListOption) (*struct {
 	Data    []App  `json:"data"`
 	Message string `json:"message"`
 	Success bool   `json:"success"`
@@ -53,13 +63,18 @@ func (s *AppService) List(ctx context.Context, projectID string) (*struct {
 		Message string `json:"message"`
 		Success bool   `json:"success"`
 	}{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+	o, err := newListOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.client.request(ctx, "app.list").
+		SetQueryParams(o.queryParams()).
 		SetResult(resp).
 		Get("/apps?projectId=" + projectID)
 	if err != nil {
 		return nil, err
 	}
+	resp.Data = filterLabels(resp.Data, o.selector, func(a App) map[string]string { return a.Metadata })
 	return resp, nil
 }
 
@@ -74,8 +89,7 @@ func (s *AppService) Get(ctx context.Context, appID string) (*struct {
 		Message string `json:"message"`
 		Success bool   `json:"success"`
 	}{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+	_, err := s.client.request(ctx, "app.get").
 		SetResult(resp).
 		Get("/apps/" + appID)
 	if err != nil {
@@ -88,6 +102,21 @@ func (s *AppService) Get(ctx context.Context, appID string) (*struct {
 type UpdateAppRequest struct {
 	Name        string `json:"name,omitempty"`
 	Description string `json:"description,omitempty"`
+	// Metadata replaces the labels of the app. A nil map leaves them
+	// unchanged and a non-nil empty map clears them.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// MarshalJSON sends a non-nil empty Metadata, which omitempty would drop
+func (r UpdateAppRequest) MarshalJSON() ([]byte, error) {
+	type fields UpdateAppRequest
+	if r.Metadata == nil {
+		return json.Marshal(fields(r))
+	}
+	return json.Marshal(struct {
+		fields
+		Metadata map[string]string `json:"metadata"`
+	}{fields(r), r.Metadata})
 }
 
 // Update an app by ID
@@ -101,8 +130,7 @@ func (s *AppService) Update(ctx context.Context, appID string, req *UpdateAppReq
 		Message string `json:"message"`
 		Success bool   `json:"success"`
 	}{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+	_, err := s.client.request(ctx, "app.update").
 		SetBody(req).
 		SetResult(resp).
 		Put("/apps/" + appID)
@@ -114,8 +142,7 @@ func (s *AppService) Update(ctx context.Context, appID string, req *UpdateAppReq
 
 // Delete an app by ID
 func (s *AppService) Delete(ctx context.Context, appID string) error {
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+	_, err := s.client.request(ctx, "app.delete").
 		Delete("/apps/" + appID)
 	return err
 }
@@ -0,0 +1,259 @@
+package vartiq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// DefaultCascadeConcurrency is how many deletes a cascading delete runs at
+// once unless WithCascadeConcurrency is given
+const DefaultCascadeConcurrency = 4
+
+// CascadeOption configures DeleteCascade
+type CascadeOption func(*cascadeOptions)
+
+type cascadeOptions struct {
+	concurrency int
+	dryRun      bool
+}
+
+// WithCascadeConcurrency limits how many deletes run at once
+func WithCascadeConcurrency(n int) CascadeOption {
+	return func(o *cascadeOptions) {
+		if n > 0 {
+			o.concurrency = n
+		}
+	}
+}
+
+// WithDryRun lists the resources a cascading delete would remove without
+// deleting anything
+func WithDryRun() CascadeOption {
+	return func(o *cascadeOptions) {
+		o.dryRun = true
+	}
+}
+
+// CascadeResource is a resource removed by a cascading delete
+type CascadeResource struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// CascadeFailure is a resource a cascading delete could not remove
+type CascadeFailure struct {
+	CascadeResource
+	Error string `json:"error"`
+	err   error
+}
+
+// Unwrap returns the error that made the delete fail
+func (f CascadeFailure) Unwrap() error { return f.err }
+
+// CascadeReport describes the outcome of a cascading delete. Resources are
+// listed in the order they were deleted: webhooks, then apps, then the
+// project. In a dry run, Deleted lists what would be deleted.
+type CascadeReport struct {
+	DryRun  bool              `json:"dryRun,omitempty"`
+	Deleted []CascadeResource `json:"deleted"`
+	Failed  []CascadeFailure  `json:"failed,omitempty"`
+	// Skipped lists parents that were kept because a child failed to delete
+	Skipped []CascadeResource `json:"skipped,omitempty"`
+}
+
+// Err returns an error joining every failure, or nil if all deletes succeeded
+func (r *CascadeReport) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	errs := make([]error, len(r.Failed))
+	for i, f := range r.Failed {
+		errs[i] = fmt.Errorf("delete %s %s: %w", f.Kind, f.ID, f.err)
+	}
+	return fmt.Errorf("vartiq: cascading delete failed for %d of %d resources: %w",
+		len(r.Failed), len(r.Failed)+len(r.Deleted)+len(r.Skipped), errors.Join(errs...))
+}
+
+// cascade deletes resources level by level
+type cascade struct {
+	client *Client
+	opts   cascadeOptions
+	report *CascadeReport
+	// failed holds the IDs of parents with a child that was not deleted
+	failed map[string]bool
+}
+
+func newCascade(client *Client, opts []CascadeOption) *cascade {
+	o := cascadeOptions{concurrency: DefaultCascadeConcurrency}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &cascade{
+		client: client,
+		opts:   o,
+		report: &CascadeReport{DryRun: o.dryRun, Deleted: []CascadeResource{}},
+		failed: make(map[string]bool),
+	}
+}
+
+// node is a resource to delete and the parent that depends on it
+type node struct {
+	CascadeResource
+	parent string
+}
+
+// webhooks lists the webhooks of an app
+func (c *cascade) webhooks(ctx context.Context, app App) ([]node, error) {
+	resp, err := c.client.Webhook.GetAll(ctx, app.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, unsuccessful(ctx, "list webhooks of app "+app.ID, resp.Message)
+	}
+	nodes := make([]node, len(resp.Data))
+	for i, w := range resp.Data {
+		nodes[i] = node{CascadeResource{Kind: "webhook", ID: w.ID, Name: w.Name}, app.ID}
+	}
+	return nodes, nil
+}
+
+// delete removes one level of resources, at most opts.concurrency at a time.
+// Resources whose own children failed are skipped.
+func (c *cascade) delete(ctx context.Context, nodes []node) {
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, c.opts.concurrency)
+	)
+	for _, n := range nodes {
+		// goroutines of this level write c.failed and c.report while the
+		// loop is still reading them
+		mu.Lock()
+		if c.failed[n.ID] {
+			c.report.Skipped = append(c.report.Skipped, n.CascadeResource)
+			c.failed[n.parent] = true
+			mu.Unlock()
+			continue
+		}
+		if c.opts.dryRun {
+			c.report.Deleted = append(c.report.Deleted, n.CascadeResource)
+			mu.Unlock()
+			continue
+		}
+		mu.Unlock()
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(n node) {
+			defer wg.Done()
+			err := c.client.deleteResource(ctx, n.Kind+".delete", "/"+n.Kind+"s/"+n.ID)
+			<-sem
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				c.report.Failed = append(c.report.Failed, CascadeFailure{n.CascadeResource, err.Error(), err})
+				c.failed[n.parent] = true
+				return
+			}
+			c.report.Deleted = append(c.report.Deleted, n.CascadeResource)
+		}(n)
+	}
+	wg.Wait()
+}
+
+// deleteResource deletes path and reports error statuses as an *APIError.
+// A resource that is already gone counts as deleted.
+func (c *Client) deleteResource(ctx context.Context, operation, path string) error {
+	var body struct {
+		Message string `json:"message"`
+	}
+	resp, err := c.request(ctx, operation).SetError(&body).Delete(path)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() == http.StatusNotFound || !resp.IsError() {
+		return nil
+	}
+	return &APIError{Message: errorMessage(resp, body.Message), Code: resp.StatusCode()}
+}
+
+func errorMessage(resp *resty.Response, message string) string {
+	if message != "" {
+		return message
+	}
+	return resp.Status()
+}
+
+// DeleteCascade deletes a project together with its apps and their webhooks.
+// Webhooks are deleted first, then apps, then the project. An app is kept if
+// one of its webhooks could not be deleted, and the project is kept if one of
+// its apps was kept, so a retry can pick up where the last attempt stopped.
+//
+// The report lists what was deleted, what failed and what was skipped. The
+// returned error is non-nil if the resources could not be listed or if any
+// delete failed.
+func (s *ProjectService) DeleteCascade(ctx context.Context, projectID string, opts ...CascadeOption) (*CascadeReport, error) {
+	ctx = WithResponseStatus(ctx)
+	c := newCascade(s.client, opts)
+
+	project, err := s.Get(ctx, projectID)
+	if err != nil {
+		return c.report, err
+	}
+	if !project.Success {
+		return c.report, unsuccessful(ctx, "get project "+projectID, project.Message)
+	}
+	apps, err := s.client.App.List(ctx, projectID)
+	if err != nil {
+		return c.report, err
+	}
+	if !apps.Success {
+		return c.report, unsuccessful(ctx, "list apps", apps.Message)
+	}
+
+	var webhooks, appNodes []node
+	for _, app := range apps.Data {
+		nodes, err := c.webhooks(ctx, app)
+		if err != nil {
+			return c.report, err
+		}
+		webhooks = append(webhooks, nodes...)
+		appNodes = append(appNodes, node{CascadeResource{Kind: "app", ID: app.ID, Name: app.Name}, projectID})
+	}
+
+	c.delete(ctx, webhooks)
+	c.delete(ctx, appNodes)
+	c.delete(ctx, []node{{CascadeResource: CascadeResource{Kind: "project", ID: projectID, Name: project.Data.Name}}})
+	return c.report, c.report.Err()
+}
+
+// DeleteCascade deletes an app together with its webhooks. The app is kept
+// if one of its webhooks could not be deleted. See
+// ProjectService.DeleteCascade for the report and error.
+func (s *AppService) DeleteCascade(ctx context.Context, appID string, opts ...CascadeOption) (*CascadeReport, error) {
+	ctx = WithResponseStatus(ctx)
+	c := newCascade(s.client, opts)
+
+	app, err := s.Get(ctx, appID)
+	if err != nil {
+		return c.report, err
+	}
+	if !app.Success {
+		return c.report, unsuccessful(ctx, "get app "+appID, app.Message)
+	}
+	webhooks, err := c.webhooks(ctx, app.Data)
+	if err != nil {
+		return c.report, err
+	}
+
+	c.delete(ctx, webhooks)
+	c.delete(ctx, []node{{CascadeResource: CascadeResource{Kind: "app", ID: appID, Name: app.Data.Name}}})
+	return c.report, c.report.Err()
+}
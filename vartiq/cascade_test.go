@@ -0,0 +1,117 @@
+package vartiq
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/internal/apitest"
+)
+
+func kinds(resources []CascadeResource) map[string]int {
+	out := make(map[string]int)
+	for _, r := range resources {
+		out[r.Kind]++
+	}
+	return out
+}
+
+func TestProjectService_DeleteCascade(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	projectID := seedProject(api)
+
+	report, err := client.Project.DeleteCascade(context.Background(), projectID, WithCascadeConcurrency(2))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"webhook": 3, "app": 2, "project": 1}, kinds(report.Deleted))
+	assert.Equal(t, "project", report.Deleted[len(report.Deleted)-1].Kind)
+	assert.Equal(t, "webhook", report.Deleted[0].Kind)
+	assert.Empty(t, api.List(apitest.Projects))
+	assert.Empty(t, api.List(apitest.Apps))
+	assert.Empty(t, api.List(apitest.Webhooks))
+}
+
+func TestProjectService_DeleteCascadeDryRun(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	projectID := seedProject(api)
+
+	report, err := client.Project.DeleteCascade(context.Background(), projectID, WithDryRun())
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Len(t, report.Deleted, 6)
+	assert.Zero(t, api.Count(http.MethodDelete))
+	assert.Len(t, api.List(apitest.Webhooks), 3)
+}
+
+func TestProjectService_DeleteCascadePartialFailure(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	projectID := seedProject(api)
+	api.FailNext("DELETE /webhooks/webhook-4", http.StatusInternalServerError)
+
+	report, err := client.Project.DeleteCascade(context.Background(), projectID)
+	require.Error(t, err)
+	assert.Equal(t, ErrorClassServer, ClassifyError(err))
+	require.Len(t, report.Failed, 1)
+	assert.Equal(t, "webhook-4", report.Failed[0].ID)
+	assert.Equal(t, []CascadeResource{
+		{Kind: "app", ID: "app-2", Name: "orders"},
+		{Kind: "project", ID: projectID, Name: "shop"},
+	}, report.Skipped)
+	assert.Equal(t, map[string]int{"webhook": 2, "app": 1}, kinds(report.Deleted))
+
+	_, ok := api.Get(apitest.Apps, "app-2")
+	assert.True(t, ok)
+
+	// a second run finishes the teardown
+	report, err = client.Project.DeleteCascade(context.Background(), projectID)
+	require.NoError(t, err)
+	assert.Len(t, report.Deleted, 3)
+	assert.Empty(t, api.List(apitest.Projects))
+}
+
+func TestAppService_DeleteCascade(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	seedProject(api)
+
+	report, err := client.App.DeleteCascade(context.Background(), "app-2")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"webhook": 2, "app": 1}, kinds(report.Deleted))
+	assert.Len(t, api.List(apitest.Apps), 1)
+	assert.Len(t, api.List(apitest.Webhooks), 1)
+
+	_, err = client.App.DeleteCascade(context.Background(), "app-2")
+	assert.Error(t, err)
+}
+
+func TestProjectService_DeleteCascadeManyFailures(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	projectID := api.Add(apitest.Projects, apitest.Object{"name": "shop"})
+	for i := 0; i < 4; i++ {
+		appID := api.Add(apitest.Apps, apitest.Object{"name": "app", "projectId": projectID})
+		for j := 0; j < 8; j++ {
+			webhookID := api.Add(apitest.Webhooks, apitest.Object{"name": "hook", "url": "https://h.example.com", "app": appID})
+			if j%2 == 0 {
+				api.FailNext("DELETE /webhooks/"+webhookID, http.StatusInternalServerError)
+			}
+		}
+	}
+
+	// run with -race: failures from one level are recorded while the loop
+	// is still scheduling the rest of it
+	report, err := client.Project.DeleteCascade(context.Background(), projectID, WithCascadeConcurrency(8))
+	require.Error(t, err)
+	assert.Len(t, report.Failed, 16)
+	assert.Equal(t, map[string]int{"webhook": 16}, kinds(report.Deleted))
+	assert.Equal(t, map[string]int{"app": 4, "project": 1}, kinds(report.Skipped))
+}
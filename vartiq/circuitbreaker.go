@@ -0,0 +1,265 @@
+package vartiq
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the API while the client's
+// circuit breaker is open
+var ErrCircuitOpen = errors.New("vartiq: circuit breaker is open")
+
+// CircuitState is the state of a circuit breaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects all requests with ErrCircuitOpen
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of trial requests through
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerSettings configures a CircuitBreaker. Zero values are
+// replaced with the defaults noted on each field.
+type CircuitBreakerSettings struct {
+	// FailureRatio is the share of failed requests that opens the circuit (0.5)
+	FailureRatio float64
+	// MinRequests is the number of requests in an interval before the
+	// failure ratio is considered (10)
+	MinRequests int
+	// Interval is how often the request counts are reset while closed (60s)
+	Interval time.Duration
+	// CoolDown is how long the circuit stays open before trial requests (30s)
+	CoolDown time.Duration
+	// HalfOpenRequests is how many trial requests must succeed to close
+	// the circuit again (1)
+	HalfOpenRequests int
+	// IsFailure reports whether a request outcome counts as a failure. By
+	// default transport errors, 429 and 5xx responses are failures.
+	IsFailure func(resp *http.Response, err error) bool
+	// OnStateChange is called after the circuit changes state
+	OnStateChange func(from, to CircuitState)
+}
+
+// CircuitBreaker stops sending requests to the API after too many of them
+// have failed, then lets trial requests through after a cool-down.
+type CircuitBreaker struct {
+	settings CircuitBreakerSettings
+
+	mu          sync.Mutex
+	state       CircuitState
+	generation  uint64
+	requests    int
+	failures    int
+	successes   int
+	inFlight    int
+	windowStart time.Time
+	openedAt    time.Time
+}
+
+// NewCircuitBreaker creates a closed circuit breaker
+func NewCircuitBreaker(settings CircuitBreakerSettings) *CircuitBreaker {
+	if settings.FailureRatio <= 0 {
+		settings.FailureRatio = 0.5
+	}
+	if settings.MinRequests <= 0 {
+		settings.MinRequests = 10
+	}
+	if settings.Interval <= 0 {
+		settings.Interval = time.Minute
+	}
+	if settings.CoolDown <= 0 {
+		settings.CoolDown = 30 * time.Second
+	}
+	if settings.HalfOpenRequests <= 0 {
+		settings.HalfOpenRequests = 1
+	}
+	if settings.IsFailure == nil {
+		settings.IsFailure = defaultIsFailure
+	}
+	return &CircuitBreaker{settings: settings, windowStart: time.Now()}
+}
+
+func defaultIsFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// State returns the current state of the circuit
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	state, change := b.advance(time.Now())
+	b.mu.Unlock()
+	b.notify(change)
+	return state
+}
+
+type stateChange struct {
+	from, to CircuitState
+}
+
+// advance moves an open circuit to half-open once the cool-down has passed
+// and resets the counts of a closed circuit at the end of each interval.
+// It must be called with b.mu held.
+func (b *CircuitBreaker) advance(now time.Time) (CircuitState, *stateChange) {
+	switch b.state {
+	case CircuitOpen:
+		if now.Sub(b.openedAt) >= b.settings.CoolDown {
+			change := b.setState(CircuitHalfOpen, now)
+			return b.state, change
+		}
+	case CircuitClosed:
+		if now.Sub(b.windowStart) >= b.settings.Interval {
+			b.requests, b.failures = 0, 0
+			b.windowStart = now
+		}
+	}
+	return b.state, nil
+}
+
+// setState must be called with b.mu held
+func (b *CircuitBreaker) setState(to CircuitState, now time.Time) *stateChange {
+	from := b.state
+	if from == to {
+		return nil
+	}
+	b.state = to
+	b.generation++
+	b.requests, b.failures, b.successes, b.inFlight = 0, 0, 0, 0
+	b.windowStart = now
+	if to == CircuitOpen {
+		b.openedAt = now
+	}
+	return &stateChange{from: from, to: to}
+}
+
+func (b *CircuitBreaker) notify(change *stateChange) {
+	if change != nil && b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(change.from, change.to)
+	}
+}
+
+// allow reports whether a request may be sent and returns the generation it
+// belongs to
+func (b *CircuitBreaker) allow() (uint64, error) {
+	b.mu.Lock()
+	state, change := b.advance(time.Now())
+	var err error
+	switch state {
+	case CircuitOpen:
+		err = ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.inFlight >= b.settings.HalfOpenRequests {
+			err = ErrCircuitOpen
+		} else {
+			b.inFlight++
+		}
+	}
+	generation := b.generation
+	b.mu.Unlock()
+	b.notify(change)
+	return generation, err
+}
+
+// record counts the outcome of a request allowed in generation
+func (b *CircuitBreaker) record(generation uint64, failed bool) {
+	b.mu.Lock()
+	now := time.Now()
+	_, change := b.advance(now)
+	if generation != b.generation {
+		b.mu.Unlock()
+		b.notify(change)
+		return
+	}
+
+	switch b.state {
+	case CircuitClosed:
+		b.requests++
+		if failed {
+			b.failures++
+		}
+		if b.requests >= b.settings.MinRequests &&
+			float64(b.failures)/float64(b.requests) >= b.settings.FailureRatio {
+			change = b.setState(CircuitOpen, now)
+		}
+	case CircuitHalfOpen:
+		b.inFlight--
+		if failed {
+			change = b.setState(CircuitOpen, now)
+		} else {
+			b.successes++
+			if b.successes >= b.settings.HalfOpenRequests {
+				change = b.setState(CircuitClosed, now)
+			}
+		}
+	}
+	b.mu.Unlock()
+	b.notify(change)
+}
+
+// release gives back a half-open trial slot without recording an outcome
+func (b *CircuitBreaker) release(generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if generation == b.generation && b.state == CircuitHalfOpen {
+		b.inFlight--
+	}
+}
+
+// WithCircuitBreaker makes the client fail fast with ErrCircuitOpen while
+// the API is failing
+func WithCircuitBreaker(settings CircuitBreakerSettings) Option {
+	return func(c *Client) {
+		c.breaker = NewCircuitBreaker(settings)
+	}
+}
+
+// CircuitState returns the state of the client's circuit breaker. A client
+// without a circuit breaker is always closed.
+func (c *Client) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	return c.breaker.State()
+}
+
+// breakerTransport rejects requests while the circuit is open and records
+// the outcome of the requests it lets through
+type breakerTransport struct {
+	breaker *CircuitBreaker
+	base    http.RoundTripper
+}
+
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	generation, err := t.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil && req.Context().Err() != nil {
+		// The caller gave up; that says nothing about the API's health
+		t.breaker.release(generation)
+		return resp, err
+	}
+	t.breaker.record(generation, t.breaker.settings.IsFailure(resp, err))
+	return resp, err
+}
@@ -0,0 +1,121 @@
+package vartiq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitState_String(t *testing.T) {
+	assert.Equal(t, "closed", CircuitClosed.String())
+	assert.Equal(t, "open", CircuitOpen.String())
+	assert.Equal(t, "half-open", CircuitHalfOpen.String())
+}
+
+func TestCircuitBreaker_Transitions(t *testing.T) {
+	var changes []string
+	b := NewCircuitBreaker(CircuitBreakerSettings{
+		FailureRatio: 0.5,
+		MinRequests:  4,
+		CoolDown:     20 * time.Millisecond,
+		OnStateChange: func(from, to CircuitState) {
+			changes = append(changes, from.String()+"->"+to.String())
+		},
+	})
+
+	for _, failed := range []bool{false, true, false, true} {
+		gen, err := b.allow()
+		require.NoError(t, err)
+		b.record(gen, failed)
+	}
+	assert.Equal(t, CircuitOpen, b.State())
+	_, err := b.allow()
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	time.Sleep(25 * time.Millisecond)
+	assert.Equal(t, CircuitHalfOpen, b.State())
+	gen, err := b.allow()
+	require.NoError(t, err)
+
+	// Only one trial request is let through while half-open
+	_, err = b.allow()
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	b.record(gen, false)
+	assert.Equal(t, CircuitClosed, b.State())
+	assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->closed"}, changes)
+}
+
+func TestCircuitBreaker_HalfOpenFailureReopens(t *testing.T) {
+	b := NewCircuitBreaker(CircuitBreakerSettings{MinRequests: 1, CoolDown: time.Millisecond})
+	gen, err := b.allow()
+	require.NoError(t, err)
+	b.record(gen, true)
+	assert.Equal(t, CircuitOpen, b.State())
+
+	time.Sleep(2 * time.Millisecond)
+	gen, err = b.allow()
+	require.NoError(t, err)
+	b.record(gen, true)
+	_, err = b.allow()
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+}
+
+func TestCircuitBreaker_IgnoresStaleGeneration(t *testing.T) {
+	b := NewCircuitBreaker(CircuitBreakerSettings{MinRequests: 1})
+	stale, err := b.allow()
+	require.NoError(t, err)
+	gen, err := b.allow()
+	require.NoError(t, err)
+
+	b.record(gen, true)
+	assert.Equal(t, CircuitOpen, b.State())
+
+	// A request that started before the circuit opened does not close it
+	b.record(stale, false)
+	assert.Equal(t, CircuitOpen, b.State())
+}
+
+func TestClient_CircuitBreakerFailsFast(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var opened bool
+	client := NewWithOptions("test-key",
+		WithBaseURL(server.URL),
+		WithCircuitBreaker(CircuitBreakerSettings{
+			MinRequests: 2,
+			CoolDown:    time.Minute,
+			OnStateChange: func(from, to CircuitState) {
+				opened = to == CircuitOpen
+			},
+		}),
+	)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Project.List(ctx)
+		require.NoError(t, err)
+	}
+	assert.True(t, opened)
+	assert.Equal(t, CircuitOpen, client.CircuitState())
+
+	_, err := client.WebhookMessage.Create(ctx, "app-1", map[string]string{"hello": "world"})
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestClient_CircuitStateWithoutBreaker(t *testing.T) {
+	assert.Equal(t, CircuitClosed, New("test-key").CircuitState())
+}
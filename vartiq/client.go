@@ -3,12 +3,16 @@
 package vartiq
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 )
@@ -17,37 +21,142 @@ import (
 type Client struct {
 	baseURL string
 	apiKey  string
+	region  Region
 	resty   *resty.Client
 
+	rateLimiter     *RateLimiter
+	serviceLimiters map[string]*RateLimiter
+	breaker         *CircuitBreaker
+	middleware      []Middleware
+	metrics         Metrics
+	credentials     CredentialsProvider
+	retry           *RetryConfig
+	timeout         time.Duration
+	httpTransport   http.RoundTripper
+	ensureLocks     keyedMutex
+
 	Project        *ProjectService
 	App            *AppService
 	Webhook        *WebhookService
 	WebhookMessage *WebhookMessageService
+	EventType      *EventTypeService
+}
+
+// Option configures a Client created with NewWithOptions
+type Option func(*Client)
+
+// WithBaseURL sets the API base URL
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		if url != "" {
+			c.baseURL = url
+		}
+	}
 }
 
-// New creates a new Vartiq API client. If baseURL is not provided, it defaults to https://api.us.vartiq.com
+// WithHTTPTransport sends requests through rt instead of a transport owned
+// by the client, so several clients can share one connection pool
+func WithHTTPTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.httpTransport = rt
+	}
+}
+
+// New creates a new Vartiq API client. If baseURL is not provided, it defaults to the endpoint of the
+// region named by the VARTIQ_REGION environment variable, or https://api.us.vartiq.com
 func New(apiKey string, baseURL ...string) *Client {
-	url := "https://api.us.vartiq.com"
-	if len(baseURL) > 0 && baseURL[0] != "" {
-		url = baseURL[0]
+	var opts []Option
+	if len(baseURL) > 0 {
+		opts = append(opts, WithBaseURL(baseURL[0]))
 	}
-	r := resty.New().SetBaseURL(url).SetHeader("x-api-key", apiKey)
-	c := &Client{
-		baseURL: url,
-		apiKey:  apiKey,
-		resty:   r,
+	return NewWithOptions(apiKey, opts...)
+}
+
+// NewWithOptions creates a new Vartiq API client configured by opts
+func NewWithOptions(apiKey string, opts ...Option) *Client {
+	c := &Client{apiKey: apiKey}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.resolveRegion()
+	r := resty.New().SetBaseURL(c.baseURL)
+	if c.credentials == nil {
+		r.SetHeader("x-api-key", apiKey)
+	}
+	if c.timeout > 0 {
+		r.SetTimeout(c.timeout)
+	}
+	base := c.httpTransport
+	if base == nil {
+		base = r.GetClient().Transport
 	}
+	c.resty = r.SetTransport(c.transport(base)).OnAfterResponse(recordStatus)
 	c.Project = &ProjectService{client: c}
 	c.App = &AppService{client: c}
 	c.Webhook = &WebhookService{client: c}
 	c.WebhookMessage = &WebhookMessageService{client: c}
+	c.EventType = &EventTypeService{client: c}
+	return c
+}
+
+type callKey struct{}
+
+// call describes the SDK operation an API request belongs to
+type call struct {
+	operation string
+	retries   int32
+}
+
+func callFrom(ctx context.Context) *call {
+	c, _ := ctx.Value(callKey{}).(*call)
 	return c
 }
 
+// Operation returns the name of the SDK operation, such as "webhook.create",
+// that issued the request carrying ctx. It returns "" outside of an SDK call.
+func Operation(ctx context.Context) string {
+	if c := callFrom(ctx); c != nil {
+		return c.operation
+	}
+	return ""
+}
+
+// RetryCount returns how many times the request carrying ctx has been
+// retried so far by the client, for example after a 429 response
+func RetryCount(ctx context.Context) int {
+	if c := callFrom(ctx); c != nil {
+		return int(atomic.LoadInt32(&c.retries))
+	}
+	return 0
+}
+
+// countRetry records that the request carrying ctx is about to be retried
+func (c *Client) countRetry(ctx context.Context) {
+	if call := callFrom(ctx); call != nil {
+		atomic.AddInt32(&call.retries, 1)
+	}
+	if c.metrics != nil {
+		c.metrics.ObserveRetry(Operation(ctx))
+	}
+}
+
+// request starts a resty request for the named operation
+func (c *Client) request(ctx context.Context, operation string) *resty.Request {
+	return c.resty.R().SetContext(context.WithValue(ctx, callKey{}, &call{operation: operation}))
+}
+
 // Verify checks the signature of a webhook payload.
 // It takes the raw payload bytes, the signature string from the header, and the webhook secret.
 // It returns the payload bytes if the signature is valid, otherwise returns an error.
 func (c *Client) Verify(payload []byte, signature, secret string) ([]byte, error) {
+	verified, err := verify(payload, signature, secret)
+	if c.metrics != nil {
+		c.metrics.ObserveVerification(err == nil)
+	}
+	return verified, err
+}
+
+func verify(payload []byte, signature, secret string) ([]byte, error) {
 	if signature == "" {
 		return nil, errors.New("signature header is missing")
 	}
@@ -1,6 +1,7 @@
 package vartiq
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -24,3 +25,16 @@ func TestNewClient_CustomBaseURL(t *testing.T) {
 	client := New(apiKey, baseURL)
 	assert.Equal(t, baseURL, client.baseURL)
 }
+
+func TestNewWithOptions_BaseURL(t *testing.T) {
+	client := NewWithOptions("test-key", WithBaseURL("https://custom.example.com"))
+	assert.Equal(t, "https://custom.example.com", client.baseURL)
+	assert.Nil(t, client.rateLimiter)
+}
+
+func TestOperation(t *testing.T) {
+	client := New("test-key")
+	req := client.request(context.Background(), "webhook.create")
+	assert.Equal(t, "webhook.create", Operation(req.Context()))
+	assert.Equal(t, "", Operation(context.Background()))
+}
@@ -0,0 +1,95 @@
+package vartiq
+
+import (
+	"context"
+)
+
+// CloneOption configures AppService.Clone
+type CloneOption func(*cloneOptions)
+
+type cloneOptions struct {
+	name       string
+	rewriteURL func(Webhook) string
+}
+
+// WithCloneName names the new app. By default it keeps the source app's name.
+func WithCloneName(name string) CloneOption {
+	return func(o *cloneOptions) {
+		o.name = name
+	}
+}
+
+// WithURLRewrite sets the URL of each cloned webhook to the value returned by
+// rewrite, for example to point a staging copy at staging endpoints
+func WithURLRewrite(rewrite func(w Webhook) string) CloneOption {
+	return func(o *cloneOptions) {
+		o.rewriteURL = rewrite
+	}
+}
+
+// CloneResult describes the app and webhooks created by Clone
+type CloneResult struct {
+	AppID string `json:"appId"`
+	// IDs maps the IDs of the source app and webhooks to their copies
+	IDs map[string]string `json:"ids"`
+}
+
+// Clone creates a copy of an app in targetProjectID and recreates each of its
+// webhooks with the same custom headers and auth. The copies get new signing
+// secrets.
+//
+// Clone stops at the first error and returns the result of what was created
+// so far.
+func (s *AppService) Clone(ctx context.Context, appID, targetProjectID string, opts ...CloneOption) (*CloneResult, error) {
+	ctx = WithResponseStatus(ctx)
+	var o cloneOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	result := &CloneResult{IDs: make(map[string]string)}
+
+	app, err := s.Get(ctx, appID)
+	if err != nil {
+		return result, err
+	}
+	if !app.Success {
+		return result, unsuccessful(ctx, "get app "+appID, app.Message)
+	}
+	webhooks, err := s.client.Webhook.GetAll(ctx, appID)
+	if err != nil {
+		return result, err
+	}
+	if !webhooks.Success {
+		return result, unsuccessful(ctx, "list webhooks of app "+appID, webhooks.Message)
+	}
+
+	name := o.name
+	if name == "" {
+		name = app.Data.Name
+	}
+	created, err := s.Create(ctx, &CreateAppRequest{Name: name, ProjectID: targetProjectID, Description: app.Data.Description, Metadata: app.Data.Metadata})
+	if err != nil {
+		return result, err
+	}
+	if !created.Success {
+		return result, unsuccessful(ctx, "create app "+name, created.Message)
+	}
+	result.AppID = created.Data.ID
+	result.IDs[appID] = created.Data.ID
+
+	for _, w := range webhooks.Data {
+		req := webhookCreateRequest(w, created.Data.ID)
+		if o.rewriteURL != nil {
+			req.URL = o.rewriteURL(w)
+		}
+		copied, err := s.client.Webhook.Create(ctx, req)
+		if err != nil {
+			return result, err
+		}
+		if !copied.Success {
+			return result, unsuccessful(ctx, "create webhook "+w.Name, copied.Message)
+		}
+		result.IDs[w.ID] = copied.Data.ID
+	}
+	return result, nil
+}
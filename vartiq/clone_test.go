@@ -0,0 +1,60 @@
+package vartiq
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/internal/apitest"
+)
+
+func TestAppService_Clone(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	seedProject(api)
+	staging := api.Add(apitest.Projects, apitest.Object{"name": "staging"})
+
+	result, err := client.App.Clone(context.Background(), "app-2", staging,
+		WithCloneName("orders-staging"),
+		WithURLRewrite(func(w Webhook) string {
+			return strings.Replace(w.URL, "example.com", "staging.example.com", 1)
+		}))
+	require.NoError(t, err)
+	assert.Equal(t, result.IDs["app-2"], result.AppID)
+	require.Len(t, result.IDs, 3)
+
+	app, ok := api.Get(apitest.Apps, result.AppID)
+	require.True(t, ok)
+	assert.Equal(t, "orders-staging", app["name"])
+	assert.Equal(t, staging, app["projectId"])
+
+	fulfilment, ok := api.Get(apitest.Webhooks, result.IDs["webhook-4"])
+	require.True(t, ok)
+	assert.Equal(t, "https://f.staging.example.com", fulfilment["url"])
+	assert.Equal(t, result.AppID, fulfilment["app"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"key": "X-Env", "value": "prod"}}, fulfilment["customHeaders"])
+
+	crm, ok := api.Get(apitest.Webhooks, result.IDs["webhook-5"])
+	require.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"method": "basic", "userName": "u", "password": "p"}, crm["auth"])
+}
+
+func TestAppService_ClonePartial(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	projectID := seedProject(api)
+	api.FailNext("POST /webhooks", http.StatusBadRequest)
+
+	result, err := client.App.Clone(context.Background(), "app-2", projectID)
+	require.Error(t, err)
+	assert.NotEmpty(t, result.AppID)
+	assert.Len(t, result.IDs, 1)
+
+	app, _ := api.Get(apitest.Apps, result.AppID)
+	assert.Equal(t, "orders", app["name"])
+}
@@ -0,0 +1,352 @@
+package vartiq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables that override the selected profile
+const (
+	ConfigFileEnvVar = "VARTIQ_CONFIG_FILE"
+	ProfileEnvVar    = "VARTIQ_PROFILE"
+	BaseURLEnvVar    = "VARTIQ_API_URL"
+	TimeoutEnvVar    = "VARTIQ_TIMEOUT"
+	MaxRetriesEnvVar = "VARTIQ_MAX_RETRIES"
+)
+
+// DefaultProfileName is used when no profile is selected
+const DefaultProfileName = "default"
+
+// ErrProfileNotFound is returned when a named profile is not in the config
+var ErrProfileNotFound = errors.New("vartiq: profile not found")
+
+// Duration is a time.Duration that decodes from strings such as "30s" or
+// from a number of seconds
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		parsed, err := parseDuration(s)
+		if err != nil {
+			return err
+		}
+		*d = parsed
+		return nil
+	}
+	var secs float64
+	if err := json.Unmarshal(b, &secs); err != nil {
+		return fmt.Errorf("vartiq: invalid duration %s", b)
+	}
+	*d = Duration(secs * float64(time.Second))
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func parseDuration(s string) (Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		return Duration(secs * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("vartiq: invalid duration %q", s)
+	}
+	return Duration(d), nil
+}
+
+// Profile holds the settings of one named configuration profile
+type Profile struct {
+	APIKey  string       `json:"apiKey,omitempty"`
+	BaseURL string       `json:"baseUrl,omitempty"`
+	Region  string       `json:"region,omitempty"`
+	Timeout Duration     `json:"timeout,omitempty"`
+	Retry   *RetryConfig `json:"retry,omitempty"`
+}
+
+// Config is the contents of a Vartiq configuration file
+type Config struct {
+	DefaultProfile string             `json:"defaultProfile,omitempty"`
+	Profiles       map[string]Profile `json:"profiles"`
+}
+
+// DefaultConfigPath returns the configuration file location: the value of
+// VARTIQ_CONFIG_FILE, or ~/.vartiq/config
+func DefaultConfigPath() (string, error) {
+	if path := os.Getenv(ConfigFileEnvVar); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".vartiq", "config"), nil
+}
+
+// LoadConfig reads a configuration file. The file may be JSON or TOML:
+//
+//	default_profile = "staging"
+//
+//	[profiles.staging]
+//	api_key = "..."
+//	region = "eu"
+//	timeout = "10s"
+//
+//	[profiles.staging.retry]
+//	max_retries = 3
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("vartiq: failed to read config: %w", err)
+	}
+
+	trimmed := bytes.TrimSpace(b)
+	if len(trimmed) > 0 && trimmed[0] != '{' {
+		doc, err := parseTOML(b)
+		if err != nil {
+			return nil, fmt.Errorf("vartiq: failed to parse config %s: %w", path, err)
+		}
+		if b, err = json.Marshal(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := &Config{}
+	if err := json.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("vartiq: failed to parse config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Profile returns the named profile. An empty name selects the profile named
+// by VARTIQ_PROFILE, then the config's default profile, then "default".
+func (c *Config) Profile(name string) (Profile, error) {
+	name = c.profileName(name)
+	p, ok := c.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	return p, nil
+}
+
+func (c *Config) profileName(name string) string {
+	if name == "" {
+		name = os.Getenv(ProfileEnvVar)
+	}
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		name = DefaultProfileName
+	}
+	return name
+}
+
+// ApplyEnv overrides the profile with the VARTIQ_* environment variables
+// that are set. A region from the environment replaces the profile's base
+// URL unless VARTIQ_API_URL is set too.
+func (p *Profile) ApplyEnv() error {
+	if v := os.Getenv(APIKeyEnvVar); v != "" {
+		p.APIKey = v
+	}
+	if v := os.Getenv(RegionEnvVar); v != "" {
+		p.Region = v
+		p.BaseURL = ""
+	}
+	if v := os.Getenv(BaseURLEnvVar); v != "" {
+		p.BaseURL = v
+	}
+	if v := os.Getenv(TimeoutEnvVar); v != "" {
+		d, err := parseDuration(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", TimeoutEnvVar, err)
+		}
+		p.Timeout = d
+	}
+	if v := os.Getenv(MaxRetriesEnvVar); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("vartiq: invalid %s %q", MaxRetriesEnvVar, v)
+		}
+		if p.Retry == nil {
+			p.Retry = &RetryConfig{}
+		}
+		p.Retry.MaxRetries = n
+	}
+	return nil
+}
+
+// Options returns the client options described by the profile
+func (p Profile) Options() ([]Option, error) {
+	var opts []Option
+	if p.Region != "" {
+		r, err := ParseRegion(p.Region)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithRegion(r))
+	}
+	if p.BaseURL != "" {
+		opts = append(opts, WithBaseURL(p.BaseURL))
+	}
+	if p.Timeout > 0 {
+		opts = append(opts, WithTimeout(time.Duration(p.Timeout)))
+	}
+	if p.Retry != nil && p.Retry.MaxRetries > 0 {
+		opts = append(opts, WithRetry(*p.Retry))
+	}
+	return opts, nil
+}
+
+// NewFromConfig creates a client from a profile in the default configuration
+// file, overridden by VARTIQ_* environment variables. An empty profile name
+// selects the profile as described on Config.Profile. Without a
+// configuration file, or without a "default" profile when no profile is
+// named, the client is configured from the environment alone.
+// Extra options are applied after the profile's options.
+func NewFromConfig(profile string, opts ...Option) (*Client, error) {
+	path, err := DefaultConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	var p Profile
+	cfg, err := LoadConfig(path)
+	switch {
+	case err == nil:
+		p, err = cfg.Profile(profile)
+		implicit := profile == "" && os.Getenv(ProfileEnvVar) == "" && cfg.DefaultProfile == ""
+		if err != nil && !(implicit && errors.Is(err, ErrProfileNotFound)) {
+			return nil, err
+		}
+	case errors.Is(err, os.ErrNotExist) && profile == "":
+		// No config file; rely on the environment
+	default:
+		return nil, err
+	}
+
+	if err := p.ApplyEnv(); err != nil {
+		return nil, err
+	}
+	if p.APIKey == "" {
+		return nil, ErrNoAPIKey
+	}
+	profileOpts, err := p.Options()
+	if err != nil {
+		return nil, err
+	}
+	return NewWithOptions(p.APIKey, append(profileOpts, opts...)...), nil
+}
+
+// parseTOML parses the subset of TOML used by configuration files: tables,
+// dotted table names, and string, number and boolean values. Field keys are
+// converted from snake_case to the camelCase used by the JSON format; table
+// names are kept as written since they include profile names.
+func parseTOML(b []byte) (map[string]interface{}, error) {
+	root := make(map[string]interface{})
+	table := root
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: invalid table header", n)
+			}
+			table = root
+			for _, part := range strings.Split(strings.Trim(line, "[]"), ".") {
+				key := unquoteKey(part)
+				if key == "" {
+					return nil, fmt.Errorf("line %d: invalid table name", n)
+				}
+				next, ok := table[key].(map[string]interface{})
+				if !ok {
+					next = make(map[string]interface{})
+					table[key] = next
+				}
+				table = next
+			}
+			continue
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", n)
+		}
+		value, err := tomlValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		table[tomlKey(key)] = value
+	}
+	return root, scanner.Err()
+}
+
+// stripComment removes a trailing # comment that is not inside a string
+func stripComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func unquoteKey(key string) string {
+	return strings.Trim(strings.TrimSpace(key), `"'`)
+}
+
+func tomlKey(key string) string {
+	parts := strings.Split(unquoteKey(key), "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func tomlValue(raw string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		return strconv.Unquote(raw)
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") {
+			return nil, fmt.Errorf("unterminated string %s", raw)
+		}
+		return raw[1 : len(raw)-1], nil
+	case raw == "true":
+		return true, nil
+	case raw == "false":
+		return false, nil
+	}
+	if n, err := strconv.ParseFloat(strings.ReplaceAll(raw, "_", ""), 64); err == nil {
+		return n, nil
+	}
+	return nil, fmt.Errorf("unsupported value %s", raw)
+}
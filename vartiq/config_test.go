@@ -0,0 +1,228 @@
+package vartiq
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clearConfigEnv unsets every variable that NewFromConfig reads
+func clearConfigEnv(t *testing.T) {
+	for _, name := range []string{
+		ConfigFileEnvVar, ProfileEnvVar, APIKeyEnvVar, BaseURLEnvVar,
+		RegionEnvVar, TimeoutEnvVar, MaxRetriesEnvVar,
+	} {
+		t.Setenv(name, "")
+	}
+}
+
+func writeConfig(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+const tomlConfig = `
+# Vartiq profiles
+default_profile = "staging"
+
+[profiles.staging]
+api_key = "staging-key" # inline comment
+region = "eu"
+timeout = "10s"
+
+[profiles.staging.retry]
+max_retries = 3
+wait_time = "50ms"
+
+[profiles.production]
+api_key = 'prod-key'
+base_url = "https://vartiq.internal.example.com"
+timeout = 30
+`
+
+func TestLoadConfig_TOML(t *testing.T) {
+	clearConfigEnv(t)
+	cfg, err := LoadConfig(writeConfig(t, tomlConfig))
+	require.NoError(t, err)
+
+	assert.Equal(t, "staging", cfg.DefaultProfile)
+	staging, err := cfg.Profile("")
+	require.NoError(t, err)
+	assert.Equal(t, "staging-key", staging.APIKey)
+	assert.Equal(t, "eu", staging.Region)
+	assert.Equal(t, Duration(10*time.Second), staging.Timeout)
+	require.NotNil(t, staging.Retry)
+	assert.Equal(t, 3, staging.Retry.MaxRetries)
+	assert.Equal(t, Duration(50*time.Millisecond), staging.Retry.WaitTime)
+
+	production, err := cfg.Profile("production")
+	require.NoError(t, err)
+	assert.Equal(t, "prod-key", production.APIKey)
+	assert.Equal(t, "https://vartiq.internal.example.com", production.BaseURL)
+	assert.Equal(t, Duration(30*time.Second), production.Timeout)
+
+	_, err = cfg.Profile("missing")
+	assert.ErrorIs(t, err, ErrProfileNotFound)
+}
+
+func TestLoadConfig_TOMLProfileNames(t *testing.T) {
+	clearConfigEnv(t)
+	path := writeConfig(t, `
+[profiles.prod_eu]
+api_key = "eu-key"
+region = "eu"
+
+[profiles.prod_eu.retry]
+max_retries = 2
+`)
+	t.Setenv(ConfigFileEnvVar, path)
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	p, err := cfg.Profile("prod_eu")
+	require.NoError(t, err)
+	assert.Equal(t, "eu-key", p.APIKey)
+	assert.Equal(t, 2, p.Retry.MaxRetries)
+
+	client, err := NewFromConfig("prod_eu")
+	require.NoError(t, err)
+	assert.Equal(t, "eu-key", client.apiKey)
+	assert.Equal(t, "https://api.eu.vartiq.com", client.baseURL)
+}
+
+func TestLoadConfig_JSON(t *testing.T) {
+	clearConfigEnv(t)
+	cfg, err := LoadConfig(writeConfig(t, `{
+		"profiles": {
+			"default": {"apiKey": "json-key", "region": "us", "retry": {"maxRetries": 2}}
+		}
+	}`))
+	require.NoError(t, err)
+
+	p, err := cfg.Profile("")
+	require.NoError(t, err)
+	assert.Equal(t, "json-key", p.APIKey)
+	assert.Equal(t, 2, p.Retry.MaxRetries)
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	_, err := LoadConfig(writeConfig(t, "[profiles.default\napi_key = 1"))
+	assert.Error(t, err)
+	_, err = LoadConfig(writeConfig(t, "api_key"))
+	assert.Error(t, err)
+	_, err = LoadConfig(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestConfig_ProfileFromEnv(t *testing.T) {
+	clearConfigEnv(t)
+	cfg, err := LoadConfig(writeConfig(t, tomlConfig))
+	require.NoError(t, err)
+
+	t.Setenv(ProfileEnvVar, "production")
+	p, err := cfg.Profile("")
+	require.NoError(t, err)
+	assert.Equal(t, "prod-key", p.APIKey)
+}
+
+func TestProfile_ApplyEnv(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv(APIKeyEnvVar, "env-key")
+	t.Setenv(TimeoutEnvVar, "5s")
+	t.Setenv(MaxRetriesEnvVar, "4")
+
+	p := Profile{APIKey: "file-key", Region: "eu"}
+	require.NoError(t, p.ApplyEnv())
+	assert.Equal(t, "env-key", p.APIKey)
+	assert.Equal(t, "eu", p.Region)
+	assert.Equal(t, Duration(5*time.Second), p.Timeout)
+	assert.Equal(t, 4, p.Retry.MaxRetries)
+
+	t.Setenv(MaxRetriesEnvVar, "many")
+	assert.Error(t, p.ApplyEnv())
+}
+
+func TestProfile_ApplyEnvRegion(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv(RegionEnvVar, "eu")
+
+	p := Profile{BaseURL: "https://vartiq.internal.example.com"}
+	require.NoError(t, p.ApplyEnv())
+	assert.Equal(t, "eu", p.Region)
+	assert.Empty(t, p.BaseURL)
+
+	t.Setenv(BaseURLEnvVar, "https://override.example.com")
+	p = Profile{BaseURL: "https://vartiq.internal.example.com"}
+	require.NoError(t, p.ApplyEnv())
+	assert.Equal(t, "https://override.example.com", p.BaseURL)
+}
+
+func TestNewFromConfig(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv(ConfigFileEnvVar, writeConfig(t, tomlConfig))
+
+	client, err := NewFromConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, "staging-key", client.apiKey)
+	assert.Equal(t, "https://api.eu.vartiq.com", client.baseURL)
+	assert.Equal(t, 10*time.Second, client.timeout)
+	require.NotNil(t, client.retry)
+	assert.Equal(t, 3, client.retry.MaxRetries)
+
+	t.Setenv(BaseURLEnvVar, "https://override.example.com")
+	client, err = NewFromConfig("production")
+	require.NoError(t, err)
+	assert.Equal(t, "prod-key", client.apiKey)
+	assert.Equal(t, "https://override.example.com", client.baseURL)
+
+	_, err = NewFromConfig("missing")
+	assert.ErrorIs(t, err, ErrProfileNotFound)
+}
+
+func TestNewFromConfig_WithoutFile(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv(ConfigFileEnvVar, filepath.Join(t.TempDir(), "missing"))
+
+	_, err := NewFromConfig("")
+	assert.ErrorIs(t, err, ErrNoAPIKey)
+
+	t.Setenv(APIKeyEnvVar, "env-key")
+	client, err := NewFromConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, "env-key", client.apiKey)
+
+	_, err = NewFromConfig("staging")
+	assert.Error(t, err)
+}
+
+func TestNewFromConfig_WithoutDefaultProfile(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv(ConfigFileEnvVar, writeConfig(t, "[profiles.staging]\napi_key = \"staging-key\"\n"))
+	t.Setenv(APIKeyEnvVar, "env-key")
+	t.Setenv(RegionEnvVar, "eu")
+
+	client, err := NewFromConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, "env-key", client.apiKey)
+	assert.Equal(t, "https://api.eu.vartiq.com", client.baseURL)
+
+	_, err = NewFromConfig("default")
+	assert.ErrorIs(t, err, ErrProfileNotFound)
+
+	t.Setenv(ProfileEnvVar, "missing")
+	_, err = NewFromConfig("")
+	assert.ErrorIs(t, err, ErrProfileNotFound)
+}
+
+func TestDuration_UnmarshalJSON(t *testing.T) {
+	var d Duration
+	require.NoError(t, d.UnmarshalJSON([]byte(`"1m30s"`)))
+	assert.Equal(t, Duration(90*time.Second), d)
+	require.NoError(t, d.UnmarshalJSON([]byte(`1.5`)))
+	assert.Equal(t, Duration(1500*time.Millisecond), d)
+	assert.Error(t, d.UnmarshalJSON([]byte(`"soon"`)))
+}
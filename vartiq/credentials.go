@@ -0,0 +1,204 @@
+package vartiq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIKeyEnvVar is the environment variable read by EnvCredentials by default
+const APIKeyEnvVar = "VARTIQ_API_KEY"
+
+// ErrNoAPIKey is returned when a credentials provider has no API key
+var ErrNoAPIKey = errors.New("vartiq: no API key available")
+
+// CredentialsProvider supplies the API key for each request, so long-running
+// services can rotate keys without restarting
+type CredentialsProvider interface {
+	// APIKey returns the key to send with the next request
+	APIKey(ctx context.Context) (string, error)
+	// Refresh reloads the key after the API rejected it with 401
+	Refresh(ctx context.Context) error
+}
+
+// WithCredentials makes the client ask p for the API key before every
+// request instead of using the key passed to NewWithOptions. A request
+// rejected with 401 is retried once after refreshing the key.
+func WithCredentials(p CredentialsProvider) Option {
+	return func(c *Client) {
+		c.credentials = p
+	}
+}
+
+// StaticCredentials always returns the same key
+type StaticCredentials string
+
+func (s StaticCredentials) APIKey(ctx context.Context) (string, error) {
+	if s == "" {
+		return "", ErrNoAPIKey
+	}
+	return string(s), nil
+}
+
+func (s StaticCredentials) Refresh(ctx context.Context) error {
+	return nil
+}
+
+// EnvCredentials reads the key from an environment variable on every
+// request. The variable defaults to VARTIQ_API_KEY.
+type EnvCredentials struct {
+	Name string
+}
+
+func (e EnvCredentials) APIKey(ctx context.Context) (string, error) {
+	name := e.Name
+	if name == "" {
+		name = APIKeyEnvVar
+	}
+	key := strings.TrimSpace(os.Getenv(name))
+	if key == "" {
+		return "", fmt.Errorf("%w: %s is not set", ErrNoAPIKey, name)
+	}
+	return key, nil
+}
+
+func (e EnvCredentials) Refresh(ctx context.Context) error {
+	return nil
+}
+
+// CredentialsFunc adapts a callback to CredentialsProvider. The callback is
+// called for every request, so it should cache the key itself if fetching
+// it is expensive.
+type CredentialsFunc func(ctx context.Context) (string, error)
+
+func (f CredentialsFunc) APIKey(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+func (f CredentialsFunc) Refresh(ctx context.Context) error {
+	return nil
+}
+
+// FileCredentials reads the key from a file and watches it for changes. The
+// file is checked at most once per interval, and reloaded whenever its
+// modification time or size changes, so a key written by a secrets manager
+// is picked up on the next request.
+type FileCredentials struct {
+	path     string
+	interval time.Duration
+
+	mu        sync.Mutex
+	key       string
+	modTime   time.Time
+	size      int64
+	checkedAt time.Time
+}
+
+// NewFileCredentials creates a provider for the key stored in path. An
+// interval of zero checks the file before every request.
+func NewFileCredentials(path string, interval time.Duration) (*FileCredentials, error) {
+	f := &FileCredentials{path: path, interval: interval}
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *FileCredentials) APIKey(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if time.Since(f.checkedAt) >= f.interval {
+		info, err := os.Stat(f.path)
+		if err != nil {
+			return "", fmt.Errorf("vartiq: failed to read credentials file: %w", err)
+		}
+		f.checkedAt = time.Now()
+		if !info.ModTime().Equal(f.modTime) || info.Size() != f.size {
+			if err := f.load(); err != nil {
+				return "", err
+			}
+		}
+	}
+	return f.key, nil
+}
+
+// Refresh rereads the file regardless of the watch interval
+func (f *FileCredentials) Refresh(ctx context.Context) error {
+	return f.reload()
+}
+
+func (f *FileCredentials) reload() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.load()
+}
+
+// load must be called with f.mu held
+func (f *FileCredentials) load() error {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return fmt.Errorf("vartiq: failed to read credentials file: %w", err)
+	}
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		return fmt.Errorf("vartiq: failed to read credentials file: %w", err)
+	}
+	key := strings.TrimSpace(string(b))
+	if key == "" {
+		return fmt.Errorf("%w: %s is empty", ErrNoAPIKey, f.path)
+	}
+	f.key = key
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	f.checkedAt = time.Now()
+	return nil
+}
+
+// credentialsTransport sets the API key header on every request and retries
+// once with a refreshed key when the API answers 401
+type credentialsTransport struct {
+	client *Client
+	base   http.RoundTripper
+}
+
+func (t *credentialsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	provider := t.client.credentials
+
+	key, err := provider.APIKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(withAPIKey(req, key))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	retry, ok := rewind(req)
+	if !ok {
+		return resp, nil
+	}
+	if err := provider.Refresh(ctx); err != nil {
+		return resp, nil
+	}
+	fresh, err := provider.APIKey(ctx)
+	if err != nil || fresh == key {
+		return resp, nil
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	t.client.countRetry(ctx)
+	return t.base.RoundTrip(withAPIKey(retry, fresh))
+}
+
+func withAPIKey(req *http.Request, key string) *http.Request {
+	out := req.Clone(req.Context())
+	out.Header.Set("x-api-key", key)
+	return out
+}
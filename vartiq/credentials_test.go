@@ -0,0 +1,139 @@
+package vartiq
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticCredentials(t *testing.T) {
+	key, err := StaticCredentials("key-1").APIKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", key)
+
+	_, err = StaticCredentials("").APIKey(context.Background())
+	assert.ErrorIs(t, err, ErrNoAPIKey)
+}
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv("MY_VARTIQ_KEY", "key-env")
+	key, err := EnvCredentials{Name: "MY_VARTIQ_KEY"}.APIKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "key-env", key)
+
+	t.Setenv(APIKeyEnvVar, "")
+	_, err = EnvCredentials{}.APIKey(context.Background())
+	assert.ErrorIs(t, err, ErrNoAPIKey)
+}
+
+func TestFileCredentials_PicksUpChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-key")
+	require.NoError(t, os.WriteFile(path, []byte("key-1\n"), 0o600))
+
+	creds, err := NewFileCredentials(path, 0)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	key, err := creds.APIKey(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", key)
+
+	require.NoError(t, os.WriteFile(path, []byte("key-two\n"), 0o600))
+	key, err = creds.APIKey(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "key-two", key)
+}
+
+func TestFileCredentials_Errors(t *testing.T) {
+	dir := t.TempDir()
+	_, err := NewFileCredentials(filepath.Join(dir, "missing"), time.Minute)
+	assert.Error(t, err)
+
+	empty := filepath.Join(dir, "empty")
+	require.NoError(t, os.WriteFile(empty, []byte("\n"), 0o600))
+	_, err = NewFileCredentials(empty, time.Minute)
+	assert.ErrorIs(t, err, ErrNoAPIKey)
+}
+
+// rotatingCredentials hands out the current key and switches to the next
+// one when refreshed
+type rotatingCredentials struct {
+	mu        sync.Mutex
+	keys      []string
+	refreshes int
+}
+
+func (r *rotatingCredentials) APIKey(ctx context.Context) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.keys[0], nil
+}
+
+func (r *rotatingCredentials) Refresh(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refreshes++
+	if len(r.keys) > 1 {
+		r.keys = r.keys[1:]
+	}
+	return nil
+}
+
+func TestClient_WithCredentials_RetriesAfterUnauthorized(t *testing.T) {
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("x-api-key")
+		seen = append(seen, key)
+		if key != "new-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"id":"project-1"},"success":true}`))
+	}))
+	defer server.Close()
+
+	creds := &rotatingCredentials{keys: []string{"old-key", "new-key"}}
+	client := NewWithOptions("", WithBaseURL(server.URL), WithCredentials(creds))
+
+	resp, err := client.Project.Create(context.Background(), &CreateProjectRequest{Name: "Test"})
+	require.NoError(t, err)
+	assert.Equal(t, "project-1", resp.Data.ID)
+	assert.Equal(t, []string{"old-key", "new-key"}, seen)
+	assert.Equal(t, 1, creds.refreshes)
+
+	// The new key is used straight away from now on
+	_, err = client.Project.Get(context.Background(), "project-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old-key", "new-key", "new-key"}, seen)
+}
+
+func TestClient_WithCredentials_RetriesOnlyOnce(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	creds := &rotatingCredentials{keys: []string{"a", "b", "c"}}
+	client := NewWithOptions("", WithBaseURL(server.URL), WithCredentials(creds))
+
+	_, err := client.App.Get(context.Background(), "app-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestClient_WithCredentials_ProviderError(t *testing.T) {
+	client := NewWithOptions("", WithCredentials(StaticCredentials("")))
+	_, err := client.App.Get(context.Background(), "app-1")
+	assert.ErrorIs(t, err, ErrNoAPIKey)
+}
@@ -0,0 +1,161 @@
+package declarative
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+// Apply carries out the changes of a plan in order. It stops at the first
+// change that fails and returns the changes applied so far, with the IDs of
+// created resources filled in.
+func Apply(ctx context.Context, client *vartiq.Client, plan *Plan) ([]Change, error) {
+	ids := make(map[string]string, len(plan.ids))
+	for path, id := range plan.ids {
+		ids[path] = id
+	}
+
+	ctx = vartiq.WithResponseStatus(ctx)
+	var applied []Change
+	for _, c := range plan.Changes {
+		id, err := apply(ctx, client, c, ids[c.parent])
+		if err != nil {
+			return applied, fmt.Errorf("declarative: failed to %s %s %s: %w", c.Action, c.Kind, c.Path, err)
+		}
+		if c.Action == ActionCreate {
+			c.ID = id
+			ids[c.Path] = id
+		}
+		applied = append(applied, c)
+	}
+	return applied, nil
+}
+
+// metadata returns labels to send in an update, where a nil map would leave
+// the live labels unchanged instead of clearing them
+func metadata(labels map[string]string) map[string]string {
+	if labels == nil {
+		return map[string]string{}
+	}
+	return labels
+}
+
+func apply(ctx context.Context, client *vartiq.Client, c Change, parentID string) (string, error) {
+	switch c.Kind {
+	case KindProject:
+		return applyProject(ctx, client, c)
+	case KindApp:
+		return applyApp(ctx, client, c, parentID)
+	case KindWebhook:
+		return applyWebhook(ctx, client, c, parentID)
+	}
+	return "", fmt.Errorf("unknown resource kind %q", c.Kind)
+}
+
+func applyProject(ctx context.Context, client *vartiq.Client, c Change) (string, error) {
+	switch c.Action {
+	case ActionCreate:
+		resp, err := client.Project.Create(ctx, &vartiq.CreateProjectRequest{Name: c.project.Name, Description: c.project.Description, Metadata: c.project.Metadata})
+		if err != nil {
+			return "", err
+		}
+		return resp.Data.ID, vartiq.CheckResponse(ctx, resp.Success, resp.Message)
+	case ActionUpdate:
+		req := &vartiq.UpdateProjectRequest{}
+		if slices.Contains(c.Fields, "description") {
+			req.Description = c.project.Description
+		}
+		if slices.Contains(c.Fields, "metadata") {
+			req.Metadata = metadata(c.project.Metadata)
+		}
+		resp, err := client.Project.Update(ctx, c.ID, req)
+		if err != nil {
+			return "", err
+		}
+		return c.ID, vartiq.CheckResponse(ctx, resp.Success, resp.Message)
+	}
+	return c.ID, client.Project.Delete(ctx, c.ID)
+}
+
+func applyApp(ctx context.Context, client *vartiq.Client, c Change, projectID string) (string, error) {
+	switch c.Action {
+	case ActionCreate:
+		resp, err := client.App.Create(ctx, &vartiq.CreateAppRequest{Name: c.app.Name, ProjectID: projectID, Description: c.app.Description,
+			Metadata: c.app.Metadata})
+		if err != nil {
+			return "", err
+		}
+		return resp.Data.ID, vartiq.CheckResponse(ctx, resp.Success, resp.Message)
+	case ActionUpdate:
+		req := &vartiq.UpdateAppRequest{}
+		if slices.Contains(c.Fields, "description") {
+			req.Description = c.app.Description
+		}
+		if slices.Contains(c.Fields, "metadata") {
+			req.Metadata = metadata(c.app.Metadata)
+		}
+		resp, err := client.App.Update(ctx, c.ID, req)
+		if err != nil {
+			return "", err
+		}
+		return c.ID, vartiq.CheckResponse(ctx, resp.Success, resp.Message)
+	}
+	return c.ID, client.App.Delete(ctx, c.ID)
+}
+
+func applyWebhook(ctx context.Context, client *vartiq.Client, c Change, appID string) (string, error) {
+	switch c.Action {
+	case ActionCreate:
+		w := c.webhook
+		req := &vartiq.CreateWebhookRequest{Name: w.Name, URL: w.URL, AppID: appID, CustomHeaders: w.CustomHeaders, Metadata: w.Metadata,
+			EventTypes: w.EventTypes, RetryPolicy: w.RetryPolicy}
+		if w.Auth != nil {
+			req.AuthMethod = string(w.Auth.Method)
+			req.UserName = w.Auth.UserName
+			req.Password = w.Auth.Password
+			req.APIKey = w.Auth.APIKey
+			req.APIKeyHeader = w.Auth.APIKeyHeader
+			req.HMACHeader = w.Auth.HMACHeader
+			req.HMACSecret = w.Auth.HMACSecret
+		}
+		resp, err := client.Webhook.Create(ctx, req)
+		if err != nil {
+			return "", err
+		}
+		return resp.Data.ID, vartiq.CheckResponse(ctx, resp.Success, resp.Message)
+	case ActionUpdate:
+		update := make(map[string]interface{})
+		for _, field := range c.Fields {
+			switch field {
+			case "url":
+				update["url"] = c.webhook.URL
+			case "customHeaders":
+				headers := c.webhook.CustomHeaders
+				if headers == nil {
+					headers = []vartiq.Header{}
+				}
+				update["customHeaders"] = headers
+			case "auth":
+				update["auth"] = c.webhook.Auth
+			case "metadata":
+				update["metadata"] = metadata(c.webhook.Metadata)
+			case "eventTypes":
+				eventTypes := c.webhook.EventTypes
+				if eventTypes == nil {
+					eventTypes = []string{}
+				}
+				update["eventTypes"] = eventTypes
+			case "retryPolicy":
+				update["retryPolicy"] = c.webhook.RetryPolicy
+			}
+		}
+		resp, err := client.Webhook.Update(ctx, c.ID, update)
+		if err != nil {
+			return "", err
+		}
+		return c.ID, vartiq.CheckResponse(ctx, resp.Success, resp.Message)
+	}
+	return c.ID, client.Webhook.Delete(ctx, c.ID)
+}
@@ -0,0 +1,337 @@
+package declarative
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+// Action is what a Change does to a resource
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Kind is the type of resource a Change applies to
+type Kind string
+
+const (
+	KindProject Kind = "project"
+	KindApp     Kind = "app"
+	KindWebhook Kind = "webhook"
+)
+
+// Change is one step of a Plan
+type Change struct {
+	Action Action
+	Kind   Kind
+	// Path names the resource as project, project/app or project/app/webhook
+	Path string
+	// ID is the ID of the live resource. For creates it is set by Apply.
+	ID string
+	// Fields lists the fields an update changes
+	Fields []string
+
+	// parent is the path of the project or app the resource belongs to
+	parent  string
+	project *ProjectSpec
+	app     *AppSpec
+	webhook *WebhookSpec
+}
+
+func (c Change) String() string {
+	symbol := map[Action]string{ActionCreate: "+", ActionUpdate: "~", ActionDelete: "-"}[c.Action]
+	s := fmt.Sprintf("%s %s %s", symbol, c.Kind, c.Path)
+	if len(c.Fields) > 0 {
+		s += " (" + strings.Join(c.Fields, ", ") + ")"
+	}
+	return s
+}
+
+// Plan is the ordered list of changes that brings the live state in line
+// with a spec. Creates come before the resources nested in them, and deletes
+// after them.
+type Plan struct {
+	Changes []Change
+
+	// ids maps the path of every live resource in scope to its ID
+	ids map[string]string
+}
+
+// Empty reports whether the live state already matches the spec
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// Count returns the number of changes with the given action
+func (p *Plan) Count(action Action) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteTo prints the plan, one change per line, followed by a summary
+func (p *Plan) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	for _, c := range p.Changes {
+		b.WriteString(c.String())
+		b.WriteByte('\n')
+	}
+	if p.Empty() {
+		b.WriteString("No changes. Live state matches the spec.\n")
+	} else {
+		fmt.Fprintf(&b, "Plan: %d to create, %d to update, %d to delete.\n",
+			p.Count(ActionCreate), p.Count(ActionUpdate), p.Count(ActionDelete))
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// PlanOption configures Diff
+type PlanOption func(*planner)
+
+// WithPrune plans the deletion of apps and webhooks that are not in the
+// spec. Only apps of projects named in the spec are considered, and projects
+// themselves are never deleted, so a partial spec cannot wipe out the rest
+// of the account.
+func WithPrune() PlanOption {
+	return func(p *planner) {
+		p.prune = true
+	}
+}
+
+type planner struct {
+	client *vartiq.Client
+	prune  bool
+	plan   *Plan
+}
+
+// Diff fetches the live state of the projects in spec and returns the plan
+// that brings it in line with the spec
+func Diff(ctx context.Context, client *vartiq.Client, spec *Spec, opts ...PlanOption) (*Plan, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	ctx = vartiq.WithResponseStatus(ctx)
+	p := &planner{client: client, plan: &Plan{ids: make(map[string]string)}}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	projects, err := client.Project.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("declarative: failed to list projects: %w", err)
+	}
+	if !projects.Success {
+		return nil, fmt.Errorf("declarative: failed to list projects: %w", vartiq.CheckResponse(ctx, false, projects.Message))
+	}
+	live := make(map[string]vartiq.Project)
+	for _, project := range projects.Data {
+		live[project.Name] = project
+	}
+
+	for i := range spec.Projects {
+		want := &spec.Projects[i]
+		have, ok := live[want.Name]
+		if !ok {
+			p.add(Change{Action: ActionCreate, Kind: KindProject, Path: want.Name, project: want})
+			for j := range want.Apps {
+				p.createApp(want.Name, &want.Apps[j])
+			}
+			continue
+		}
+		p.plan.ids[want.Name] = have.ID
+		if fields := describedDiff(want.Description, have.Description, want.Metadata, have.Metadata); len(fields) > 0 {
+			p.add(Change{Action: ActionUpdate, Kind: KindProject, Path: want.Name, ID: have.ID, Fields: fields, project: want})
+		}
+		if err := p.planApps(ctx, want, have.ID); err != nil {
+			return nil, err
+		}
+	}
+	return p.plan, nil
+}
+
+func (p *planner) add(c Change) {
+	p.plan.Changes = append(p.plan.Changes, c)
+}
+
+func (p *planner) createApp(projectPath string, app *AppSpec) {
+	path := projectPath + "/" + app.Name
+	p.add(Change{Action: ActionCreate, Kind: KindApp, Path: path, parent: projectPath, app: app})
+	for i := range app.Webhooks {
+		w := &app.Webhooks[i]
+		p.add(Change{Action: ActionCreate, Kind: KindWebhook, Path: path + "/" + w.Name, parent: path, webhook: w})
+	}
+}
+
+func (p *planner) planApps(ctx context.Context, project *ProjectSpec, projectID string) error {
+	apps, err := p.client.App.List(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("declarative: failed to list apps of %s: %w", project.Name, err)
+	}
+	if !apps.Success {
+		return fmt.Errorf("declarative: failed to list apps of %s: %w", project.Name, vartiq.CheckResponse(ctx, false, apps.Message))
+	}
+	live := make(map[string]vartiq.App)
+	for _, app := range apps.Data {
+		live[app.Name] = app
+	}
+
+	wanted := make(map[string]bool)
+	for i := range project.Apps {
+		want := &project.Apps[i]
+		wanted[want.Name] = true
+		path := project.Name + "/" + want.Name
+		have, ok := live[want.Name]
+		if !ok {
+			p.createApp(project.Name, want)
+			continue
+		}
+		p.plan.ids[path] = have.ID
+		if fields := describedDiff(want.Description, have.Description, want.Metadata, have.Metadata); len(fields) > 0 {
+			p.add(Change{Action: ActionUpdate, Kind: KindApp, Path: path, ID: have.ID, Fields: fields, app: want})
+		}
+		if err := p.planWebhooks(ctx, path, want, have.ID); err != nil {
+			return err
+		}
+	}
+
+	if p.prune {
+		for _, app := range apps.Data {
+			if wanted[app.Name] {
+				continue
+			}
+			path := project.Name + "/" + app.Name
+			if err := p.pruneApp(ctx, path, app.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pruneApp plans the deletion of an app and its webhooks
+func (p *planner) pruneApp(ctx context.Context, path, appID string) error {
+	webhooks, err := p.listWebhooks(ctx, path, appID)
+	if err != nil {
+		return err
+	}
+	for _, w := range webhooks {
+		p.add(Change{Action: ActionDelete, Kind: KindWebhook, Path: path + "/" + w.Name, ID: w.ID})
+	}
+	p.add(Change{Action: ActionDelete, Kind: KindApp, Path: path, ID: appID})
+	return nil
+}
+
+func (p *planner) listWebhooks(ctx context.Context, path, appID string) ([]vartiq.Webhook, error) {
+	resp, err := p.client.Webhook.GetAll(ctx, appID)
+	if err != nil {
+		return nil, fmt.Errorf("declarative: failed to list webhooks of %s: %w", path, err)
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("declarative: failed to list webhooks of %s: %w", path, vartiq.CheckResponse(ctx, false, resp.Message))
+	}
+	return resp.Data, nil
+}
+
+func (p *planner) planWebhooks(ctx context.Context, appPath string, app *AppSpec, appID string) error {
+	webhooks, err := p.listWebhooks(ctx, appPath, appID)
+	if err != nil {
+		return err
+	}
+	live := make(map[string]vartiq.Webhook)
+	for _, w := range webhooks {
+		live[w.Name] = w
+	}
+
+	wanted := make(map[string]bool)
+	for i := range app.Webhooks {
+		want := &app.Webhooks[i]
+		wanted[want.Name] = true
+		path := appPath + "/" + want.Name
+		have, ok := live[want.Name]
+		if !ok {
+			p.add(Change{Action: ActionCreate, Kind: KindWebhook, Path: path, parent: appPath, webhook: want})
+			continue
+		}
+		p.plan.ids[path] = have.ID
+		if fields := webhookDiff(want, have); len(fields) > 0 {
+			p.add(Change{Action: ActionUpdate, Kind: KindWebhook, Path: path, ID: have.ID, Fields: fields, webhook: want})
+		}
+	}
+
+	if p.prune {
+		for _, w := range webhooks {
+			if !wanted[w.Name] {
+				p.add(Change{Action: ActionDelete, Kind: KindWebhook, Path: appPath + "/" + w.Name, ID: w.ID})
+			}
+		}
+	}
+	return nil
+}
+
+// describedDiff returns the fields in which a live project or app differs
+// from the spec
+func describedDiff(wantDescription, haveDescription string, wantMetadata, haveMetadata map[string]string) []string {
+	var fields []string
+	if wantDescription != haveDescription {
+		fields = append(fields, "description")
+	}
+	if !maps.Equal(wantMetadata, haveMetadata) {
+		fields = append(fields, "metadata")
+	}
+	return fields
+}
+
+// webhookDiff returns the fields in which the live webhook differs from the
+// spec
+func webhookDiff(want *WebhookSpec, have vartiq.Webhook) []string {
+	var fields []string
+	if want.URL != have.URL {
+		fields = append(fields, "url")
+	}
+	if len(want.CustomHeaders) != len(have.CustomHeaders) ||
+		(len(want.CustomHeaders) > 0 && !reflect.DeepEqual(want.CustomHeaders, have.CustomHeaders)) {
+		fields = append(fields, "customHeaders")
+	}
+	if !authEqual(want.Auth, have.Auth) {
+		fields = append(fields, "auth")
+	}
+	if !maps.Equal(want.Metadata, have.Metadata) {
+		fields = append(fields, "metadata")
+	}
+	if !slices.Equal(want.EventTypes, have.EventTypes) {
+		fields = append(fields, "eventTypes")
+	}
+	if !reflect.DeepEqual(want.RetryPolicy, have.RetryPolicy) {
+		fields = append(fields, "retryPolicy")
+	}
+	return fields
+}
+
+// authEqual compares the parts of webhook auth the API returns. Passwords,
+// API keys and HMAC secrets are write-only, so a changed secret is not
+// detected; rename the webhook or change its auth method to rotate one.
+func authEqual(a, b *vartiq.WebhookAuth) bool {
+	return publicAuth(a) == publicAuth(b)
+}
+
+func publicAuth(a *vartiq.WebhookAuth) vartiq.WebhookAuth {
+	if a == nil {
+		return vartiq.WebhookAuth{}
+	}
+	return vartiq.WebhookAuth{Method: a.Method, UserName: a.UserName, APIKeyHeader: a.APIKeyHeader, HMACHeader: a.HMACHeader}
+}
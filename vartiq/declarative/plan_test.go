@@ -0,0 +1,233 @@
+package declarative
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/internal/apitest"
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+func testSpec() *Spec {
+	return &Spec{Projects: []ProjectSpec{
+		{Name: "shop", Description: "Online shop", Metadata: map[string]string{"team": "commerce"}, Apps: []AppSpec{
+			{Name: "orders", Metadata: map[string]string{"tier": "1"}, Webhooks: []WebhookSpec{
+				{Name: "fulfilment", URL: "https://fulfilment.example.com", CustomHeaders: []vartiq.Header{{Key: "X-Env", Value: "prod"}},
+					Metadata: map[string]string{"owner": "ops"}},
+				{Name: "crm", URL: "https://crm.example.com", Auth: &vartiq.WebhookAuth{Method: vartiq.AuthMethodAPIKey, APIKey: "k", APIKeyHeader: "X-Key"},
+					EventTypes:  []string{"order.created"},
+					RetryPolicy: &vartiq.RetryPolicy{MaxAttempts: 3, Backoff: []vartiq.Duration{vartiq.Duration(time.Minute)}}},
+			}},
+		}},
+		{Name: "blog", Apps: []AppSpec{{Name: "comments"}}},
+	}}
+}
+
+func changes(p *Plan) []string {
+	var out []string
+	for _, c := range p.Changes {
+		out = append(out, c.String())
+	}
+	return out
+}
+
+func TestDiffAndApply_FromScratch(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := vartiq.New("key", api.URL)
+	ctx := context.Background()
+
+	plan, err := Diff(ctx, client, testSpec())
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"+ project shop",
+		"+ app shop/orders",
+		"+ webhook shop/orders/fulfilment",
+		"+ webhook shop/orders/crm",
+		"+ project blog",
+		"+ app blog/comments",
+	}, changes(plan))
+
+	applied, err := Apply(ctx, client, plan)
+	require.NoError(t, err)
+	require.Len(t, applied, 6)
+	for _, c := range applied {
+		assert.NotEmpty(t, c.ID, c.Path)
+	}
+
+	orders, ok := api.Get(apitest.Apps, applied[1].ID)
+	require.True(t, ok)
+	assert.Equal(t, applied[0].ID, orders["projectId"])
+	crm, ok := api.Get(apitest.Webhooks, applied[3].ID)
+	require.True(t, ok)
+	assert.Equal(t, applied[1].ID, crm["app"])
+	assert.Equal(t, "X-Key", crm["auth"].(map[string]interface{})["apiKeyHeader"])
+	assert.Equal(t, []interface{}{"order.created"}, crm["eventTypes"])
+	assert.Equal(t, map[string]interface{}{"maxAttempts": float64(3), "backoff": []interface{}{"1m0s"}}, crm["retryPolicy"])
+
+	plan, err = Diff(ctx, client, testSpec(), WithPrune())
+	require.NoError(t, err)
+	assert.True(t, plan.Empty(), changes(plan))
+
+	var out bytes.Buffer
+	plan.WriteTo(&out)
+	assert.Equal(t, "No changes. Live state matches the spec.\n", out.String())
+}
+
+func TestDiff_UpdatesAndPrune(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := vartiq.New("key", api.URL)
+	ctx := context.Background()
+
+	_, err := Apply(ctx, client, mustDiff(t, client, testSpec()))
+	require.NoError(t, err)
+	other := api.Add(apitest.Projects, apitest.Object{"name": "unmanaged"})
+	api.Add(apitest.Apps, apitest.Object{"name": "legacy", "projectId": other})
+
+	spec := testSpec()
+	shop := &spec.Projects[0]
+	shop.Description = "Shop"
+	shop.Apps[0].Webhooks[0].URL = "https://fulfilment.example.com/v2"
+	shop.Apps[0].Webhooks[0].CustomHeaders = nil
+	shop.Apps[0].Webhooks[0].EventTypes = []string{"order.shipped"}
+	shop.Apps[0].Webhooks = shop.Apps[0].Webhooks[:1]
+	spec.Projects = spec.Projects[:1]
+
+	plan := mustDiff(t, client, spec)
+	assert.Equal(t, []string{
+		"~ project shop (description)",
+		"~ webhook shop/orders/fulfilment (url, customHeaders, eventTypes)",
+	}, changes(plan))
+
+	plan, err = Diff(ctx, client, spec, WithPrune())
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"~ project shop (description)",
+		"~ webhook shop/orders/fulfilment (url, customHeaders, eventTypes)",
+		"- webhook shop/orders/crm",
+	}, changes(plan))
+
+	var out bytes.Buffer
+	plan.WriteTo(&out)
+	assert.Contains(t, out.String(), "Plan: 0 to create, 2 to update, 1 to delete.\n")
+
+	_, err = Apply(ctx, client, plan)
+	require.NoError(t, err)
+	assert.Len(t, api.List(apitest.Webhooks), 1)
+	fulfilment := api.List(apitest.Webhooks)[0]
+	assert.Equal(t, "https://fulfilment.example.com/v2", fulfilment["url"])
+	assert.Empty(t, fulfilment["customHeaders"])
+	assert.Equal(t, []interface{}{"order.shipped"}, fulfilment["eventTypes"])
+	assert.Len(t, api.List(apitest.Apps), 3, "apps of unmanaged projects are never pruned")
+
+	spec.Projects[0].Apps = nil
+	plan = mustDiff(t, client, spec, WithPrune())
+	assert.Equal(t, []string{"- webhook shop/orders/fulfilment", "- app shop/orders"}, changes(plan))
+}
+
+func TestDiff_Metadata(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := vartiq.New("key", api.URL)
+	ctx := context.Background()
+
+	applied, err := Apply(ctx, client, mustDiff(t, client, testSpec()))
+	require.NoError(t, err)
+	project, _ := api.Get(apitest.Projects, applied[0].ID)
+	assert.Equal(t, map[string]interface{}{"team": "commerce"}, project["metadata"])
+
+	spec := testSpec()
+	shop := &spec.Projects[0]
+	shop.Metadata = nil
+	shop.Apps[0].Metadata = map[string]string{"tier": "2"}
+	shop.Apps[0].Webhooks[0].Metadata = nil
+
+	plan := mustDiff(t, client, spec)
+	assert.Equal(t, []string{
+		"~ project shop (metadata)",
+		"~ app shop/orders (metadata)",
+		"~ webhook shop/orders/fulfilment (metadata)",
+	}, changes(plan))
+
+	_, err = Apply(ctx, client, plan)
+	require.NoError(t, err)
+	project, _ = api.Get(apitest.Projects, applied[0].ID)
+	assert.Empty(t, project["metadata"])
+	orders, _ := api.Get(apitest.Apps, applied[1].ID)
+	assert.Equal(t, map[string]interface{}{"tier": "2"}, orders["metadata"])
+	fulfilment, _ := api.Get(apitest.Webhooks, applied[2].ID)
+	assert.Empty(t, fulfilment["metadata"])
+	assert.True(t, mustDiff(t, client, spec).Empty())
+}
+
+func TestDiff_AuthIgnoresSecrets(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := vartiq.New("key", api.URL)
+	ctx := context.Background()
+
+	applied, err := Apply(ctx, client, mustDiff(t, client, testSpec()))
+	require.NoError(t, err)
+
+	// The API never returns secrets, so a spec secret can't be compared.
+	spec := testSpec()
+	crm := &spec.Projects[0].Apps[0].Webhooks[1]
+	crm.Auth.APIKey = "rotated"
+	assert.True(t, mustDiff(t, client, spec).Empty())
+
+	crm.Auth.APIKeyHeader = "X-Api-Key"
+	plan := mustDiff(t, client, spec)
+	assert.Equal(t, []string{"~ webhook shop/orders/crm (auth)"}, changes(plan))
+
+	_, err = Apply(ctx, client, plan)
+	require.NoError(t, err)
+	stored, _ := api.Get(apitest.Webhooks, applied[3].ID)
+	assert.Equal(t, "rotated", stored["auth"].(map[string]interface{})["apiKey"])
+	assert.True(t, mustDiff(t, client, spec).Empty())
+}
+
+func TestApply_UpdatesOnlyChangedFields(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := vartiq.New("key", api.URL)
+	ctx := context.Background()
+
+	applied, err := Apply(ctx, client, mustDiff(t, client, testSpec()))
+	require.NoError(t, err)
+
+	spec := testSpec()
+	spec.Projects[0].Description = "Shop"
+	spec.Projects[0].Metadata = map[string]string{"team": "platform"}
+	plan := mustDiff(t, client, spec)
+	assert.Equal(t, []string{"~ project shop (description, metadata)"}, changes(plan))
+	plan.Changes[0].Fields = []string{"metadata"}
+
+	_, err = Apply(ctx, client, plan)
+	require.NoError(t, err)
+	shop, _ := api.Get(apitest.Projects, applied[0].ID)
+	assert.Equal(t, "Online shop", shop["description"])
+	assert.Equal(t, map[string]interface{}{"team": "platform"}, shop["metadata"])
+}
+
+func TestApply_StopsOnError(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := vartiq.New("key", api.URL)
+
+	plan := mustDiff(t, client, testSpec())
+	api.FailNext("POST /webhooks", 400)
+	applied, err := Apply(context.Background(), client, plan)
+	assert.ErrorContains(t, err, "failed to create webhook shop/orders/fulfilment")
+	assert.Len(t, applied, 2)
+}
+
+func mustDiff(t *testing.T, client *vartiq.Client, spec *Spec, opts ...PlanOption) *Plan {
+	plan, err := Diff(context.Background(), client, spec, opts...)
+	require.NoError(t, err)
+	return plan
+}
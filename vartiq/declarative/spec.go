@@ -0,0 +1,177 @@
+// Package declarative keeps projects, apps and webhooks in sync with a spec
+// kept in version control. Diff compares the spec against the live state of the
+// account and Apply carries out the plan, like a small Terraform.
+package declarative
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the desired state of a set of projects. Resources are identified
+// by name: projects across the account, apps within their project and
+// webhooks within their app.
+type Spec struct {
+	Projects []ProjectSpec `json:"projects"`
+}
+
+// ProjectSpec is the desired state of a project and its apps
+type ProjectSpec struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Apps        []AppSpec         `json:"apps,omitempty"`
+}
+
+// AppSpec is the desired state of an app and its webhooks
+type AppSpec struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Webhooks    []WebhookSpec     `json:"webhooks,omitempty"`
+}
+
+// WebhookSpec is the desired state of a webhook
+type WebhookSpec struct {
+	Name          string              `json:"name"`
+	URL           string              `json:"url"`
+	CustomHeaders []vartiq.Header     `json:"customHeaders,omitempty"`
+	Auth          *vartiq.WebhookAuth `json:"auth,omitempty"`
+	Metadata      map[string]string   `json:"metadata,omitempty"`
+	EventTypes    []string            `json:"eventTypes,omitempty"`
+	RetryPolicy   *vartiq.RetryPolicy `json:"retryPolicy,omitempty"`
+}
+
+// envRef matches ${NAME} references in spec files
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// LoadSpec reads a spec file. See ParseSpec for the format.
+func LoadSpec(path string) (*Spec, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("declarative: failed to read spec: %w", err)
+	}
+	return ParseSpec(b)
+}
+
+// ParseSpec parses a YAML or JSON spec. Field names are those of the JSON
+// API, and ${NAME} references in values are replaced with environment
+// variables so secrets such as auth passwords need not be committed. The
+// references are expanded after parsing, so a variable's value is always a
+// single string and cannot change the structure of the spec. The spec is
+// validated before it is returned.
+func ParseSpec(b []byte) (*Spec, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		return nil, fmt.Errorf("declarative: failed to parse spec: %w", err)
+	}
+	var missing []string
+	expandEnv(&root, &missing)
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("declarative: environment variables not set: %v", missing)
+	}
+
+	var doc interface{}
+	if root.Kind != 0 {
+		if err := root.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("declarative: failed to parse spec: %w", err)
+		}
+	}
+	j, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("declarative: failed to parse spec: %w", err)
+	}
+	spec := &Spec{}
+	if err := json.Unmarshal(j, spec); err != nil {
+		return nil, fmt.Errorf("declarative: failed to parse spec: %w", err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// expandEnv replaces ${NAME} references in the scalar values below n. Names
+// of unset variables are added to missing.
+func expandEnv(n *yaml.Node, missing *[]string) {
+	switch n.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, c := range n.Content {
+			expandEnv(c, missing)
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(n.Content); i += 2 {
+			expandEnv(n.Content[i], missing)
+		}
+	case yaml.ScalarNode:
+		if !envRef.MatchString(n.Value) {
+			return
+		}
+		n.Value = envRef.ReplaceAllStringFunc(n.Value, func(ref string) string {
+			name := envRef.FindStringSubmatch(ref)[1]
+			v, ok := os.LookupEnv(name)
+			if !ok {
+				*missing = append(*missing, name)
+			}
+			return v
+		})
+		n.Tag = "!!str"
+	}
+}
+
+// Validate checks that every resource is named, names are unique among
+// their siblings and webhooks have a URL
+func (s *Spec) Validate() error {
+	var errs []error
+	projects := make(map[string]bool)
+	for _, p := range s.Projects {
+		if p.Name == "" {
+			errs = append(errs, errors.New("project without a name"))
+			continue
+		}
+		if projects[p.Name] {
+			errs = append(errs, fmt.Errorf("duplicate project %q", p.Name))
+		}
+		projects[p.Name] = true
+
+		apps := make(map[string]bool)
+		for _, a := range p.Apps {
+			if a.Name == "" {
+				errs = append(errs, fmt.Errorf("app without a name in project %q", p.Name))
+				continue
+			}
+			if apps[a.Name] {
+				errs = append(errs, fmt.Errorf("duplicate app %q in project %q", a.Name, p.Name))
+			}
+			apps[a.Name] = true
+
+			webhooks := make(map[string]bool)
+			for _, w := range a.Webhooks {
+				path := p.Name + "/" + a.Name + "/" + w.Name
+				switch {
+				case w.Name == "":
+					errs = append(errs, fmt.Errorf("webhook without a name in app %q", p.Name+"/"+a.Name))
+					continue
+				case webhooks[w.Name]:
+					errs = append(errs, fmt.Errorf("duplicate webhook %q", path))
+				case w.URL == "":
+					errs = append(errs, fmt.Errorf("webhook %q has no url", path))
+				}
+				if err := w.RetryPolicy.Validate(); err != nil {
+					errs = append(errs, fmt.Errorf("webhook %q: %w", path, err))
+				}
+				webhooks[w.Name] = true
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("declarative: invalid spec: %w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package declarative
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+const yamlSpec = `
+projects:
+  - name: shop
+    description: Online shop
+    apps:
+      - name: orders
+        webhooks:
+          - name: fulfilment
+            url: https://fulfilment.example.com/hooks
+            customHeaders:
+              - key: X-Env
+                value: prod
+            auth:
+              method: basic
+              userName: vartiq
+              password: ${FULFILMENT_PASSWORD}
+            retryPolicy:
+              maxAttempts: 4
+              backoff: [10s, 1m, 5m]
+              attemptTimeout: 15s
+`
+
+func TestParseSpec_YAML(t *testing.T) {
+	t.Setenv("FULFILMENT_PASSWORD", "hunter2")
+	spec, err := ParseSpec([]byte(yamlSpec))
+	require.NoError(t, err)
+
+	require.Len(t, spec.Projects, 1)
+	w := spec.Projects[0].Apps[0].Webhooks[0]
+	assert.Equal(t, "https://fulfilment.example.com/hooks", w.URL)
+	assert.Equal(t, []vartiq.Header{{Key: "X-Env", Value: "prod"}}, w.CustomHeaders)
+	assert.Equal(t, &vartiq.WebhookAuth{Method: vartiq.AuthMethodBasic, UserName: "vartiq", Password: "hunter2"}, w.Auth)
+	assert.Equal(t, &vartiq.RetryPolicy{
+		MaxAttempts:    4,
+		Backoff:        []vartiq.Duration{vartiq.Duration(10 * time.Second), vartiq.Duration(time.Minute), vartiq.Duration(5 * time.Minute)},
+		AttemptTimeout: vartiq.Duration(15 * time.Second),
+	}, w.RetryPolicy)
+}
+
+func TestParseSpec_EnvValuesAreLiteral(t *testing.T) {
+	t.Setenv("FULFILMENT_PASSWORD", "p@ss: #\"x\n  url: https://evil.example.com")
+	spec, err := ParseSpec([]byte(yamlSpec))
+	require.NoError(t, err)
+	w := spec.Projects[0].Apps[0].Webhooks[0]
+	assert.Equal(t, "p@ss: #\"x\n  url: https://evil.example.com", w.Auth.Password)
+	assert.Equal(t, "https://fulfilment.example.com/hooks", w.URL)
+
+	t.Setenv("FULFILMENT_PASSWORD", `p@ss: #"x`)
+	spec, err = ParseSpec([]byte(yamlSpec))
+	require.NoError(t, err)
+	assert.Equal(t, `p@ss: #"x`, spec.Projects[0].Apps[0].Webhooks[0].Auth.Password)
+}
+
+func TestParseSpec_MissingEnv(t *testing.T) {
+	os.Unsetenv("FULFILMENT_PASSWORD")
+	_, err := ParseSpec([]byte(yamlSpec))
+	assert.ErrorContains(t, err, "FULFILMENT_PASSWORD")
+}
+
+func TestLoadSpec_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"projects":[{"name":"shop","apps":[{"name":"orders"}]}]}`), 0o600))
+
+	spec, err := LoadSpec(path)
+	require.NoError(t, err)
+	assert.Equal(t, "orders", spec.Projects[0].Apps[0].Name)
+}
+
+func TestSpec_Validate(t *testing.T) {
+	spec := &Spec{Projects: []ProjectSpec{
+		{Name: "shop", Apps: []AppSpec{
+			{Name: "orders", Webhooks: []WebhookSpec{{Name: "a", URL: "https://a"}, {Name: "a", URL: "https://b"}, {Name: "b"},
+				{Name: "c", URL: "https://c", RetryPolicy: &vartiq.RetryPolicy{MaxAttempts: -1}}}},
+			{Name: "orders"},
+		}},
+		{Name: "shop"},
+		{},
+	}}
+	err := spec.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `duplicate webhook "shop/orders/a"`)
+	assert.ErrorContains(t, err, `webhook "shop/orders/b" has no url`)
+	assert.ErrorContains(t, err, `webhook "shop/orders/c": retryPolicy.maxAttempts must not be negative`)
+	assert.ErrorContains(t, err, `duplicate app "orders"`)
+	assert.ErrorContains(t, err, `duplicate project "shop"`)
+	assert.ErrorContains(t, err, "project without a name")
+}
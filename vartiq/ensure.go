@@ -0,0 +1,330 @@
+package vartiq
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// ErrNameRequired is returned by the Ensure methods when the request has no
+// name, since resources are matched by name
+var ErrNameRequired = errors.New("vartiq: a name is required to ensure a resource")
+
+// keyedMutex serializes Ensure calls for the same resource within a process.
+// Entries are reference counted and removed by the last holder, so the map
+// only holds keys that are in use.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.Mutex
+	refs int
+}
+
+func (k *keyedMutex) lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyedLock)
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		if l.refs--; l.refs == 0 {
+			delete(k.locks, key)
+		}
+	}
+}
+
+// oldest returns the index of the item named name that was created first,
+// or -1. Ties are broken by ID so concurrent callers agree on the result.
+func oldest[T any](items []T, name func(T) string, id func(T) string, created func(T) Time, want string) int {
+	best := -1
+	for i, item := range items {
+		if name(item) != want {
+			continue
+		}
+		if best < 0 {
+			best = i
+			continue
+		}
+		a, b := created(item), created(items[best])
+		if a.Before(b.Time) || (a.Equal(b.Time) && id(item) < id(items[best])) {
+			best = i
+		}
+	}
+	return best
+}
+
+// Ensure returns the project named req.Name, creating it if it does not
+// exist and updating its description and metadata if they differ. Empty
+// fields are not enforced. The boolean reports whether anything was created
+// or updated.
+//
+// Calls for the same name are serialized within the process. When concurrent
+// deploys still create duplicates, the oldest project wins and the copy
+// created by this call is deleted.
+func (s *ProjectService) Ensure(ctx context.Context, req *CreateProjectRequest) (*Project, bool, error) {
+	ctx = WithResponseStatus(ctx)
+	if req.Name == "" {
+		return nil, false, ErrNameRequired
+	}
+	defer s.client.ensureLocks.lock("project:" + req.Name)()
+
+	find := func() ([]Project, int, error) {
+		resp, err := s.List(ctx)
+		if err != nil {
+			return nil, -1, err
+		}
+		if !resp.Success {
+			return nil, -1, unsuccessful(ctx, "list projects", resp.Message)
+		}
+		i := oldest(resp.Data, func(p Project) string { return p.Name }, func(p Project) string { return p.ID },
+			func(p Project) Time { return p.CreatedAt }, req.Name)
+		return resp.Data, i, nil
+	}
+
+	projects, i, err := find()
+	if err != nil {
+		return nil, false, err
+	}
+	if i < 0 {
+		created, err := s.Create(ctx, req)
+		if err != nil {
+			return nil, false, err
+		}
+		if !created.Success {
+			return nil, false, unsuccessful(ctx, "create project", created.Message)
+		}
+		if projects, i, err = find(); err != nil {
+			return nil, false, err
+		}
+		if i < 0 || projects[i].ID == created.Data.ID {
+			return &created.Data, true, nil
+		}
+		if err := s.Delete(ctx, created.Data.ID); err != nil {
+			return nil, false, err
+		}
+	}
+
+	project := projects[i]
+	update := &UpdateProjectRequest{}
+	if req.Description != "" && req.Description != project.Description {
+		update.Description = req.Description
+	}
+	if len(req.Metadata) > 0 && !maps.Equal(req.Metadata, project.Metadata) {
+		update.Metadata = req.Metadata
+	}
+	if update.Description == "" && update.Metadata == nil {
+		return &project, false, nil
+	}
+	updated, err := s.Update(ctx, project.ID, update)
+	if err != nil {
+		return nil, false, err
+	}
+	if !updated.Success {
+		return nil, false, unsuccessful(ctx, "update project", updated.Message)
+	}
+	return &updated.Data, true, nil
+}
+
+// Ensure returns the app named req.Name in project req.ProjectID, creating it
+// if it does not exist and updating its description and metadata if they
+// differ. See
+// ProjectService.Ensure for how duplicates are handled.
+func (s *AppService) Ensure(ctx context.Context, req *CreateAppRequest) (*App, bool, error) {
+	ctx = WithResponseStatus(ctx)
+	if req.Name == "" {
+		return nil, false, ErrNameRequired
+	}
+	defer s.client.ensureLocks.lock("app:" + req.ProjectID + "/" + req.Name)()
+
+	find := func() ([]App, int, error) {
+		resp, err := s.List(ctx, req.ProjectID)
+		if err != nil {
+			return nil, -1, err
+		}
+		if !resp.Success {
+			return nil, -1, unsuccessful(ctx, "list apps", resp.Message)
+		}
+		i := oldest(resp.Data, func(a App) string { return a.Name }, func(a App) string { return a.ID },
+			func(a App) Time { return a.CreatedAt }, req.Name)
+		return resp.Data, i, nil
+	}
+
+	apps, i, err := find()
+	if err != nil {
+		return nil, false, err
+	}
+	if i < 0 {
+		resp, err := s.Create(ctx, req)
+		if err != nil {
+			return nil, false, err
+		}
+		if !resp.Success {
+			return nil, false, unsuccessful(ctx, "create app", resp.Message)
+		}
+		if apps, i, err = find(); err != nil {
+			return nil, false, err
+		}
+		if i < 0 || apps[i].ID == resp.Data.ID {
+			return &resp.Data, true, nil
+		}
+		if err := s.Delete(ctx, resp.Data.ID); err != nil {
+			return nil, false, err
+		}
+	}
+
+	app := apps[i]
+	update := &UpdateAppRequest{}
+	if req.Description != "" && req.Description != app.Description {
+		update.Description = req.Description
+	}
+	if len(req.Metadata) > 0 && !maps.Equal(req.Metadata, app.Metadata) {
+		update.Metadata = req.Metadata
+	}
+	if update.Description == "" && update.Metadata == nil {
+		return &app, false, nil
+	}
+	updated, err := s.Update(ctx, app.ID, update)
+	if err != nil {
+		return nil, false, err
+	}
+	if !updated.Success {
+		return nil, false, unsuccessful(ctx, "update app", updated.Message)
+	}
+	return &updated.Data, true, nil
+}
+
+// Ensure returns the webhook named req.Name in app req.AppID, creating it if
+// it does not exist. The URL, custom headers, auth and event types of an
+// existing webhook are updated to match req, and so are its metadata and
+// retry policy unless req has none. See ProjectService.Ensure for how duplicates are handled.
+func (s *WebhookService) Ensure(ctx context.Context, req *CreateWebhookRequest) (*Webhook, bool, error) {
+	ctx = WithResponseStatus(ctx)
+	if req.Name == "" {
+		return nil, false, ErrNameRequired
+	}
+	if err := validateWebhookAuth(req); err != nil {
+		return nil, false, err
+	}
+	if err := validateRetryPolicy(req.RetryPolicy); err != nil {
+		return nil, false, err
+	}
+	defer s.client.ensureLocks.lock("webhook:" + req.AppID + "/" + req.Name)()
+
+	find := func() ([]Webhook, int, error) {
+		resp, err := s.GetAll(ctx, req.AppID)
+		if err != nil {
+			return nil, -1, err
+		}
+		if !resp.Success {
+			return nil, -1, unsuccessful(ctx, "list webhooks", resp.Message)
+		}
+		i := oldest(resp.Data, func(w Webhook) string { return w.Name }, func(w Webhook) string { return w.ID },
+			func(w Webhook) Time { return w.CreatedAt }, req.Name)
+		return resp.Data, i, nil
+	}
+
+	webhooks, i, err := find()
+	if err != nil {
+		return nil, false, err
+	}
+	if i < 0 {
+		resp, err := s.Create(ctx, req)
+		if err != nil {
+			return nil, false, err
+		}
+		if !resp.Success {
+			return nil, false, unsuccessful(ctx, "create webhook", resp.Message)
+		}
+		if webhooks, i, err = find(); err != nil {
+			return nil, false, err
+		}
+		if i < 0 || webhooks[i].ID == resp.Data.ID {
+			return &resp.Data, true, nil
+		}
+		if err := s.Delete(ctx, resp.Data.ID); err != nil {
+			return nil, false, err
+		}
+	}
+
+	webhook := webhooks[i]
+	update, changed := webhookDrift(req, &webhook)
+	if !changed {
+		return &webhook, false, nil
+	}
+	updated, err := s.UpdateFields(ctx, webhook.ID, update)
+	if err != nil {
+		return nil, false, err
+	}
+	if !updated.Success {
+		return nil, false, unsuccessful(ctx, "update webhook", updated.Message)
+	}
+	return &updated.Data, true, nil
+}
+
+// webhookDrift returns the update that makes w match req
+func webhookDrift(req *CreateWebhookRequest, w *Webhook) (*UpdateWebhookRequest, bool) {
+	update := &UpdateWebhookRequest{}
+	changed := false
+	if req.URL != w.URL {
+		update.URL = req.URL
+		changed = true
+	}
+	if len(req.CustomHeaders) != len(w.CustomHeaders) ||
+		(len(req.CustomHeaders) > 0 && !reflect.DeepEqual(req.CustomHeaders, w.CustomHeaders)) {
+		update.CustomHeaders = append([]Header{}, req.CustomHeaders...)
+		changed = true
+	}
+
+	if len(req.Metadata) > 0 && !maps.Equal(req.Metadata, w.Metadata) {
+		update.Metadata = req.Metadata
+		changed = true
+	}
+	if !slices.Equal(req.EventTypes, w.EventTypes) {
+		update.EventTypes = append([]string{}, req.EventTypes...)
+		changed = true
+	}
+	if req.RetryPolicy != nil && !reflect.DeepEqual(req.RetryPolicy, w.RetryPolicy) {
+		update.RetryPolicy = req.RetryPolicy
+		changed = true
+	}
+
+	// Secrets are write-only, so only the rest of the auth can drift
+	var want *WebhookAuth
+	if req.AuthMethod != "" {
+		want = &WebhookAuth{
+			Method:       AuthMethod(req.AuthMethod),
+			UserName:     req.UserName,
+			Password:     req.Password,
+			APIKey:       req.APIKey,
+			APIKeyHeader: req.APIKeyHeader,
+			HMACHeader:   req.HMACHeader,
+			HMACSecret:   req.HMACSecret,
+		}
+	}
+	switch {
+	case want == nil && w.Auth != nil && w.Auth.Method != "":
+		update.RemoveAuth = true
+		changed = true
+	case want != nil && (w.Auth == nil || want.withoutSecrets() != w.Auth.withoutSecrets()):
+		update.Auth = want
+		changed = true
+	}
+	return update, changed
+}
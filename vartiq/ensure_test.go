@@ -0,0 +1,234 @@
+package vartiq
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/internal/apitest"
+)
+
+func TestProjectService_Ensure(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	ctx := context.Background()
+
+	project, changed, err := client.Project.Ensure(ctx, &CreateProjectRequest{Name: "shop", Description: "Shop"})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "shop", project.Name)
+
+	again, changed, err := client.Project.Ensure(ctx, &CreateProjectRequest{Name: "shop", Description: "Shop"})
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, project.ID, again.ID)
+
+	updated, changed, err := client.Project.Ensure(ctx, &CreateProjectRequest{Name: "shop", Description: "Online shop"})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "Online shop", updated.Description)
+	assert.Len(t, api.List(apitest.Projects), 1)
+
+	labelled, changed, err := client.Project.Ensure(ctx, &CreateProjectRequest{Name: "shop", Metadata: map[string]string{"team": "web"}})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "Online shop", labelled.Description)
+	assert.Equal(t, map[string]string{"team": "web"}, labelled.Metadata)
+}
+
+func TestProjectService_EnsureConcurrent(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+
+	var wg sync.WaitGroup
+	ids := make([]string, 5)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			project, _, err := client.Project.Ensure(context.Background(), &CreateProjectRequest{Name: "shop"})
+			assert.NoError(t, err)
+			ids[i] = project.ID
+		}(i)
+	}
+	wg.Wait()
+	for _, id := range ids {
+		assert.Equal(t, ids[0], id)
+	}
+	assert.Len(t, api.List(apitest.Projects), 1)
+}
+
+func TestKeyedMutex(t *testing.T) {
+	var k keyedMutex
+	var wg sync.WaitGroup
+	counts := map[string]*int{"a": new(int), "b": new(int), "c": new(int)}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := []string{"a", "b", "c"}[i%3]
+			unlock := k.lock(key)
+			defer unlock()
+			*counts[key]++
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 17, *counts["a"])
+	assert.Equal(t, 16, *counts["c"])
+	assert.Empty(t, k.locks)
+}
+
+func TestProjectService_EnsureRace(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	// another deploy creates the same project while ours is in flight
+	racer := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodPost {
+				api.Add(apitest.Projects, apitest.Object{"name": "shop"})
+			}
+			return next(req)
+		}
+	}
+	client := NewWithOptions("key", WithBaseURL(api.URL), WithMiddleware(racer))
+
+	project, changed, err := client.Project.Ensure(context.Background(), &CreateProjectRequest{Name: "shop"})
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, "project-1", project.ID)
+	assert.Len(t, api.List(apitest.Projects), 1)
+}
+
+func TestAppService_Ensure(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	ctx := context.Background()
+	projectID := api.Add(apitest.Projects, apitest.Object{"name": "shop"})
+	other := api.Add(apitest.Projects, apitest.Object{"name": "other"})
+	api.Add(apitest.Apps, apitest.Object{"name": "orders", "projectId": other})
+
+	app, changed, err := client.App.Ensure(ctx, &CreateAppRequest{ProjectID: projectID, Name: "orders"})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "orders", app.Name)
+	assert.Len(t, api.List(apitest.Apps), 2)
+
+	again, changed, err := client.App.Ensure(ctx, &CreateAppRequest{ProjectID: projectID, Name: "orders"})
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, app.ID, again.ID)
+
+	_, changed, err = client.App.Ensure(ctx, &CreateAppRequest{ProjectID: projectID, Name: "orders", Description: "Orders"})
+	require.NoError(t, err)
+	assert.True(t, changed)
+}
+
+func TestWebhookService_Ensure(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	ctx := context.Background()
+	appID := api.Add(apitest.Apps, apitest.Object{"name": "orders"})
+
+	req := &CreateWebhookRequest{
+		AppID:         appID,
+		Name:          "fulfilment",
+		URL:           "https://f.example.com",
+		CustomHeaders: []Header{{Key: "X-Env", Value: "prod"}},
+		AuthMethod:    "basic",
+		UserName:      "u",
+		Password:      "p",
+	}
+	webhook, changed, err := client.Webhook.Ensure(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	_, changed, err = client.Webhook.Ensure(ctx, req)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	req.URL = "https://g.example.com"
+	req.CustomHeaders = nil
+	req.AuthMethod, req.UserName, req.Password = "", "", ""
+	updated, changed, err := client.Webhook.Ensure(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, webhook.ID, updated.ID)
+	assert.Equal(t, "https://g.example.com", updated.URL)
+	assert.Empty(t, updated.CustomHeaders)
+	assert.Nil(t, updated.Auth)
+	assert.Len(t, api.List(apitest.Webhooks), 1)
+
+	req.RetryPolicy = &RetryPolicy{MaxAttempts: 3, Backoff: []Duration{Duration(time.Minute)}}
+	_, changed, err = client.Webhook.Ensure(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	_, changed, err = client.Webhook.Ensure(ctx, req)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	_, _, err = client.Webhook.Ensure(ctx, &CreateWebhookRequest{AppID: appID, Name: "x", URL: "https://x", AuthMethod: "basic"})
+	assert.Error(t, err)
+}
+
+func TestWebhookService_EnsureIgnoresAuthSecrets(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	ctx := context.Background()
+	appID := api.Add(apitest.Apps, apitest.Object{"name": "orders"})
+	// The API does not return auth secrets
+	api.Add(apitest.Webhooks, apitest.Object{"name": "crm", "url": "https://crm.example.com", "app": appID,
+		"auth": map[string]interface{}{"method": "basic", "userName": "u"}})
+
+	req := &CreateWebhookRequest{AppID: appID, Name: "crm", URL: "https://crm.example.com", AuthMethod: "basic", UserName: "u", Password: "p"}
+	_, changed, err := client.Webhook.Ensure(ctx, req)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	req.UserName = "v"
+	updated, changed, err := client.Webhook.Ensure(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "v", updated.Auth.UserName)
+}
+
+func TestEnsure_RequiresName(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	ctx := context.Background()
+
+	_, _, err := client.Project.Ensure(ctx, &CreateProjectRequest{})
+	assert.ErrorIs(t, err, ErrNameRequired)
+	_, _, err = client.App.Ensure(ctx, &CreateAppRequest{ProjectID: "project-1"})
+	assert.ErrorIs(t, err, ErrNameRequired)
+	_, _, err = client.Webhook.Ensure(ctx, &CreateWebhookRequest{AppID: "app-1", URL: "https://a"})
+	assert.ErrorIs(t, err, ErrNameRequired)
+	assert.Empty(t, api.Requests())
+}
+
+func TestWebhookService_UpdateFields(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	id := api.Add(apitest.Webhooks, apitest.Object{"name": "w", "url": "https://a"})
+
+	resp, err := client.Webhook.UpdateFields(context.Background(), id, &UpdateWebhookRequest{
+		Auth: &WebhookAuth{Method: "apiKey", APIKey: "k", APIKeyHeader: "X-Key"},
+	})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, "https://a", resp.Data.URL)
+	assert.Equal(t, "k", resp.Data.Auth.APIKey)
+
+	_, err = client.Webhook.UpdateFields(context.Background(), id, &UpdateWebhookRequest{Auth: &WebhookAuth{Method: "hmac"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,112 @@
+package vartiq
+
+import (
+	"context"
+	"time"
+)
+
+// EventTypeService manages the catalog of event types that messages can be
+// sent with and webhooks can subscribe to
+type EventTypeService struct {
+	client *Client
+}
+
+// EventType is an entry in the event type catalog, such as "order.created"
+type EventType struct {
+	ID             string      `json:"id"`
+	Name           string      `json:"name"`
+	Description    string      `json:"description,omitempty"`
+	ExamplePayload interface{} `json:"examplePayload,omitempty"`
+	CreatedAt      Time        `json:"createdAt"`
+	UpdatedAt      Time        `json:"updatedAt"`
+}
+
+// Created returns when the event type was registered
+func (e EventType) Created() time.Time { return e.CreatedAt.Time }
+
+// Updated returns when the event type was last updated
+func (e EventType) Updated() time.Time { return e.UpdatedAt.Time }
+
+// CreateEventTypeRequest registers an event type. ExamplePayload can be any
+// JSON-serializable value and documents what messages of this type carry.
+type CreateEventTypeRequest struct {
+	Name           string      `json:"name"`
+	Description    string      `json:"description,omitempty"`
+	ExamplePayload interface{} `json:"examplePayload,omitempty"`
+}
+
+// UpdateEventTypeRequest is used for updating an event type
+type UpdateEventTypeRequest struct {
+	Description    string      `json:"description,omitempty"`
+	ExamplePayload interface{} `json:"examplePayload,omitempty"`
+}
+
+type EventTypeResponse struct {
+	Data    EventType `json:"data"`
+	Message string    `json:"message"`
+	Success bool      `json:"success"`
+}
+
+type EventTypeListResponse struct {
+	Data    []EventType `json:"data"`
+	Message string      `json:"message"`
+	Success bool        `json:"success"`
+}
+
+// Create registers an event type
+func (s *EventTypeService) Create(ctx context.Context, req *CreateEventTypeRequest) (*EventTypeResponse, error) {
+	resp := &EventTypeResponse{}
+	_, err := s.client.request(ctx, "event_type.create").
+		SetBody(req).
+		SetResult(resp).
+		Post("/event-types")
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// List all event types
+func (s *EventTypeService) List(ctx context.Context) (*EventTypeListResponse, error) {
+	resp := &EventTypeListResponse{}
+	_, err := s.client.request(ctx, "event_type.list").
+		SetResult(resp).
+		Get("/event-types")
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Get a single event type by ID
+func (s *EventTypeService) Get(ctx context.Context, eventTypeID string) (*EventTypeResponse, error) {
+	resp := &EventTypeResponse{}
+	_, err := s.client.request(ctx, "event_type.get").
+		SetResult(resp).
+		Get("/event-types/" + eventTypeID)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Update an event type by ID
+func (s *EventTypeService) Update(ctx context.Context, eventTypeID string, req *UpdateEventTypeRequest) (*EventTypeResponse, error) {
+	resp := &EventTypeResponse{}
+	_, err := s.client.request(ctx, "event_type.update").
+		SetBody(req).
+		SetResult(resp).
+		Put("/event-types/" + eventTypeID)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Delete an event type by ID. Webhooks subscribed to it keep their
+// subscription but no longer receive messages of that type.
+func (s *EventTypeService) Delete(ctx context.Context, eventTypeID string) error {
+	_, err := s.client.request(ctx, "event_type.delete").
+		Delete("/event-types/" + eventTypeID)
+	return err
+}
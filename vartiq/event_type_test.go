@@ -0,0 +1,46 @@
+package vartiq
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/internal/apitest"
+)
+
+func TestEventTypeService(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	ctx := context.Background()
+
+	created, err := client.EventType.Create(ctx, &CreateEventTypeRequest{
+		Name:           "order.created",
+		Description:    "An order was placed",
+		ExamplePayload: map[string]interface{}{"orderId": "42"},
+	})
+	require.NoError(t, err)
+	require.True(t, created.Success)
+	assert.Equal(t, "order.created", created.Data.Name)
+	assert.Equal(t, map[string]interface{}{"orderId": "42"}, created.Data.ExamplePayload)
+
+	_, err = client.EventType.Create(ctx, &CreateEventTypeRequest{Name: "order.shipped"})
+	require.NoError(t, err)
+	list, err := client.EventType.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, list.Data, 2)
+
+	updated, err := client.EventType.Update(ctx, created.Data.ID, &UpdateEventTypeRequest{Description: "A customer placed an order"})
+	require.NoError(t, err)
+	assert.Equal(t, "A customer placed an order", updated.Data.Description)
+
+	got, err := client.EventType.Get(ctx, created.Data.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "A customer placed an order", got.Data.Description)
+
+	require.NoError(t, client.EventType.Delete(ctx, created.Data.ID))
+	got, err = client.EventType.Get(ctx, created.Data.ID)
+	require.NoError(t, err)
+	assert.False(t, got.Success)
+}
@@ -0,0 +1,240 @@
+package vartiq
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ExportVersion is the version of the export document format written by
+// Export. Import accepts documents up to this version.
+const ExportVersion = 1
+
+// ExportDocument is a project with its apps and webhooks, as written by
+// ProjectService.Export
+type ExportDocument struct {
+	Version    int           `json:"version"`
+	ExportedAt time.Time     `json:"exportedAt"`
+	Redacted   bool          `json:"redacted,omitempty"`
+	Project    Project       `json:"project"`
+	Apps       []ExportedApp `json:"apps"`
+}
+
+// ExportedApp is an app and its webhooks
+type ExportedApp struct {
+	App
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// ExportOption configures ProjectService.Export
+type ExportOption func(*exportOptions)
+
+type exportOptions struct {
+	redact bool
+}
+
+// WithRedactedSecrets leaves webhook signing secrets and auth credentials out
+// of the export
+func WithRedactedSecrets() ExportOption {
+	return func(o *exportOptions) {
+		o.redact = true
+	}
+}
+
+// unsuccessful reports a response with success set to false. ctx is the
+// context of the request, from WithResponseStatus.
+func unsuccessful(ctx context.Context, operation, message string) error {
+	return fmt.Errorf("vartiq: %s failed: %w", operation, CheckResponse(ctx, false, message))
+}
+
+// Export dumps a project, its apps and all of their webhooks
+func (s *ProjectService) Export(ctx context.Context, projectID string, opts ...ExportOption) (*ExportDocument, error) {
+	ctx = WithResponseStatus(ctx)
+	var o exportOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	project, err := s.Get(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if !project.Success {
+		return nil, unsuccessful(ctx, "get project "+projectID, project.Message)
+	}
+	doc := &ExportDocument{
+		Version:    ExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Redacted:   o.redact,
+		Project:    project.Data,
+		Apps:       []ExportedApp{},
+	}
+
+	apps, err := s.client.App.List(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if !apps.Success {
+		return nil, unsuccessful(ctx, "list apps", apps.Message)
+	}
+	for _, app := range apps.Data {
+		webhooks, err := s.client.Webhook.GetAll(ctx, app.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !webhooks.Success {
+			return nil, unsuccessful(ctx, "list webhooks of app "+app.ID, webhooks.Message)
+		}
+		exported := ExportedApp{App: app, Webhooks: webhooks.Data}
+		if exported.Webhooks == nil {
+			exported.Webhooks = []Webhook{}
+		}
+		if o.redact {
+			for i := range exported.Webhooks {
+				redactWebhook(&exported.Webhooks[i])
+			}
+		}
+		doc.Apps = append(doc.Apps, exported)
+	}
+	return doc, nil
+}
+
+func redactWebhook(w *Webhook) {
+	w.Secret = ""
+	if w.Auth != nil {
+		auth := w.Auth.withoutSecrets()
+		w.Auth = &auth
+	}
+}
+
+// ImportedResource is a resource created by Import
+type ImportedResource struct {
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+	OldID string `json:"oldId"`
+	NewID string `json:"newId"`
+}
+
+// ImportReport describes what Import created
+type ImportReport struct {
+	// ProjectID is the project the apps were imported into
+	ProjectID string `json:"projectId"`
+	// IDs maps the IDs in the document to the IDs of the created resources
+	IDs      map[string]string  `json:"ids"`
+	Created  []ImportedResource `json:"created"`
+	Warnings []string           `json:"warnings,omitempty"`
+}
+
+func (r *ImportReport) created(kind, name, oldID, newID string) {
+	r.IDs[oldID] = newID
+	r.Created = append(r.Created, ImportedResource{Kind: kind, Name: name, OldID: oldID, NewID: newID})
+}
+
+// Import recreates the apps and webhooks of doc in targetProjectID. If
+// targetProjectID is empty, the project itself is created first. Webhooks
+// get new signing secrets and are disabled or paused again if they were when
+// exported. Auth credentials of redacted documents cannot be
+// restored, so such webhooks are created without auth and listed in the
+// report's warnings.
+//
+// Import stops at the first error and returns the report of what was created
+// so far.
+func (s *ProjectService) Import(ctx context.Context, doc *ExportDocument, targetProjectID string) (*ImportReport, error) {
+	ctx = WithResponseStatus(ctx)
+	if doc.Version < 1 || doc.Version > ExportVersion {
+		return nil, fmt.Errorf("vartiq: unsupported export version %d", doc.Version)
+	}
+	report := &ImportReport{ProjectID: targetProjectID, IDs: make(map[string]string)}
+
+	if targetProjectID == "" {
+		resp, err := s.Create(ctx, &CreateProjectRequest{Name: doc.Project.Name, Description: doc.Project.Description, Metadata: doc.Project.Metadata})
+		if err != nil {
+			return report, err
+		}
+		if !resp.Success {
+			return report, unsuccessful(ctx, "create project", resp.Message)
+		}
+		report.ProjectID = resp.Data.ID
+		report.created("project", doc.Project.Name, doc.Project.ID, resp.Data.ID)
+	} else {
+		report.IDs[doc.Project.ID] = targetProjectID
+	}
+
+	for _, app := range doc.Apps {
+		resp, err := s.client.App.Create(ctx, &CreateAppRequest{Name: app.Name, ProjectID: report.ProjectID, Description: app.Description, Metadata: app.Metadata})
+		if err != nil {
+			return report, err
+		}
+		if !resp.Success {
+			return report, unsuccessful(ctx, "create app "+app.Name, resp.Message)
+		}
+		report.created("app", app.Name, app.ID, resp.Data.ID)
+
+		for _, w := range app.Webhooks {
+			req := webhookCreateRequest(w, resp.Data.ID)
+			if doc.Redacted && w.Auth != nil {
+				req = webhookCreateRequest(Webhook{Name: w.Name, URL: w.URL, CustomHeaders: w.CustomHeaders, Metadata: w.Metadata, EventTypes: w.EventTypes, RetryPolicy: w.RetryPolicy}, resp.Data.ID)
+				report.Warnings = append(report.Warnings, fmt.Sprintf("webhook %s/%s was created without %s auth because the export is redacted", app.Name, w.Name, w.Auth.Method))
+			}
+			created, err := s.client.Webhook.Create(ctx, req)
+			if err != nil {
+				return report, err
+			}
+			if !created.Success {
+				return report, unsuccessful(ctx, "create webhook "+w.Name, created.Message)
+			}
+			report.created("webhook", w.Name, w.ID, created.Data.ID)
+			if err := s.client.Webhook.restoreStatus(ctx, created.Data.ID, w); err != nil {
+				return report, err
+			}
+		}
+	}
+	return report, nil
+}
+
+// restoreStatus disables or pauses webhookID to match the exported webhook w.
+// A pause that has already ended leaves the webhook active.
+func (s *WebhookService) restoreStatus(ctx context.Context, webhookID string, w Webhook) error {
+	var (
+		resp *WebhookResponse
+		err  error
+	)
+	switch w.StatusAt(time.Now()) {
+	case WebhookStatusDisabled:
+		resp, err = s.Disable(ctx, webhookID)
+	case WebhookStatusPaused:
+		resp, err = s.Pause(ctx, webhookID, w.PausedUntil.Time)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return unsuccessful(ctx, "set status of webhook "+w.Name, resp.Message)
+	}
+	return nil
+}
+
+// webhookCreateRequest returns the request that creates a copy of w in app
+func webhookCreateRequest(w Webhook, appID string) *CreateWebhookRequest {
+	req := &CreateWebhookRequest{
+		Name:          w.Name,
+		URL:           w.URL,
+		AppID:         appID,
+		CustomHeaders: w.CustomHeaders,
+		Metadata:      w.Metadata,
+		EventTypes:    w.EventTypes,
+		RetryPolicy:   w.RetryPolicy,
+	}
+	if w.Auth != nil && w.Auth.Method != "" {
+		req.AuthMethod = string(w.Auth.Method)
+		req.UserName = w.Auth.UserName
+		req.Password = w.Auth.Password
+		req.APIKey = w.Auth.APIKey
+		req.APIKeyHeader = w.Auth.APIKeyHeader
+		req.HMACHeader = w.Auth.HMACHeader
+		req.HMACSecret = w.Auth.HMACSecret
+	}
+	return req
+}
@@ -0,0 +1,146 @@
+package vartiq
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/internal/apitest"
+)
+
+// seedProject creates a project with two apps and three webhooks
+func seedProject(api *apitest.Server) string {
+	projectID := api.Add(apitest.Projects, apitest.Object{"name": "shop", "description": "Online shop"})
+	orders := api.Add(apitest.Apps, apitest.Object{"name": "orders", "projectId": projectID})
+	billing := api.Add(apitest.Apps, apitest.Object{"name": "billing", "projectId": projectID})
+	api.Add(apitest.Webhooks, apitest.Object{"name": "fulfilment", "url": "https://f.example.com", "app": orders,
+		"customHeaders": []interface{}{map[string]interface{}{"key": "X-Env", "value": "prod"}}})
+	api.Add(apitest.Webhooks, apitest.Object{"name": "crm", "url": "https://crm.example.com", "app": orders,
+		"auth": map[string]interface{}{"method": "basic", "userName": "u", "password": "p"}})
+	api.Add(apitest.Webhooks, apitest.Object{"name": "ledger", "url": "https://ledger.example.com", "app": billing})
+	return projectID
+}
+
+func TestProjectService_Export(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	projectID := seedProject(api)
+
+	doc, err := client.Project.Export(context.Background(), projectID)
+	require.NoError(t, err)
+	assert.Equal(t, ExportVersion, doc.Version)
+	assert.Equal(t, "shop", doc.Project.Name)
+	require.Len(t, doc.Apps, 2)
+	assert.Equal(t, "orders", doc.Apps[0].Name)
+	require.Len(t, doc.Apps[0].Webhooks, 2)
+	assert.NotEmpty(t, doc.Apps[0].Webhooks[0].Secret)
+	assert.Equal(t, "p", doc.Apps[0].Webhooks[1].Auth.Password)
+
+	b, err := json.Marshal(doc)
+	require.NoError(t, err)
+	var decoded ExportDocument
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, doc.Apps[1].Webhooks[0].URL, decoded.Apps[1].Webhooks[0].URL)
+	assert.Equal(t, "billing", decoded.Apps[1].Name)
+
+	redacted, err := client.Project.Export(context.Background(), projectID, WithRedactedSecrets())
+	require.NoError(t, err)
+	assert.True(t, redacted.Redacted)
+	assert.Empty(t, redacted.Apps[0].Webhooks[0].Secret)
+	assert.Empty(t, redacted.Apps[0].Webhooks[1].Auth.Password)
+	assert.Equal(t, "u", redacted.Apps[0].Webhooks[1].Auth.UserName)
+
+	_, err = client.Project.Export(context.Background(), "missing")
+	assert.Error(t, err)
+}
+
+func TestProjectService_Import(t *testing.T) {
+	source := apitest.New()
+	defer source.Close()
+	doc, err := New("key", source.URL).Project.Export(context.Background(), seedProject(source))
+	require.NoError(t, err)
+
+	target := apitest.New()
+	defer target.Close()
+	client := New("key", target.URL)
+
+	report, err := client.Project.Import(context.Background(), doc, "")
+	require.NoError(t, err)
+	assert.Len(t, report.Created, 6)
+	assert.Empty(t, report.Warnings)
+
+	newOrders := report.IDs[doc.Apps[0].ID]
+	app, ok := target.Get(apitest.Apps, newOrders)
+	require.True(t, ok)
+	assert.Equal(t, report.ProjectID, app["projectId"])
+	crm, ok := target.Get(apitest.Webhooks, report.IDs[doc.Apps[0].Webhooks[1].ID])
+	require.True(t, ok)
+	assert.Equal(t, newOrders, crm["app"])
+	assert.Equal(t, "p", crm["auth"].(map[string]interface{})["password"])
+
+	existing := target.Add(apitest.Projects, apitest.Object{"name": "staging"})
+	doc.Redacted = true
+	report, err = client.Project.Import(context.Background(), doc, existing)
+	require.NoError(t, err)
+	assert.Equal(t, existing, report.ProjectID)
+	assert.Len(t, report.Created, 5)
+	require.Len(t, report.Warnings, 1)
+	assert.Contains(t, report.Warnings[0], "orders/crm")
+	crm, _ = target.Get(apitest.Webhooks, report.IDs[doc.Apps[0].Webhooks[1].ID])
+	assert.Nil(t, crm["auth"])
+
+	doc.Version = ExportVersion + 1
+	_, err = client.Project.Import(context.Background(), doc, existing)
+	assert.ErrorContains(t, err, "unsupported export version")
+}
+
+func TestProjectService_ImportWebhookStatus(t *testing.T) {
+	source := apitest.New()
+	defer source.Close()
+	projectID := seedProject(source)
+	sourceClient := New("key", source.URL)
+	until := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	_, err := sourceClient.Webhook.Disable(context.Background(), "webhook-4")
+	require.NoError(t, err)
+	_, err = sourceClient.Webhook.Pause(context.Background(), "webhook-5", until)
+	require.NoError(t, err)
+
+	doc, err := sourceClient.Project.Export(context.Background(), projectID)
+	require.NoError(t, err)
+	b, err := json.Marshal(doc)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"status":"disabled"`)
+	assert.Contains(t, string(b), `"pausedUntil":"`+until.Format(time.RFC3339))
+
+	target := apitest.New()
+	defer target.Close()
+	report, err := New("key", target.URL).Project.Import(context.Background(), doc, "")
+	require.NoError(t, err)
+
+	disabled, _ := target.Get(apitest.Webhooks, report.IDs["webhook-4"])
+	assert.Equal(t, "disabled", disabled["status"])
+	paused, _ := target.Get(apitest.Webhooks, report.IDs["webhook-5"])
+	assert.Equal(t, "paused", paused["status"])
+	assert.Equal(t, until.Format(time.RFC3339), paused["pausedUntil"])
+	active, _ := target.Get(apitest.Webhooks, report.IDs["webhook-6"])
+	assert.Nil(t, active["status"])
+}
+
+func TestProjectService_ImportStopsOnError(t *testing.T) {
+	source := apitest.New()
+	defer source.Close()
+	doc, err := New("key", source.URL).Project.Export(context.Background(), seedProject(source))
+	require.NoError(t, err)
+
+	target := apitest.New()
+	defer target.Close()
+	target.FailNext("POST /webhooks", 500)
+
+	report, err := New("key", target.URL).Project.Import(context.Background(), doc, "")
+	require.Error(t, err)
+	assert.Len(t, report.Created, 2)
+}
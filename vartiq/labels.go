@@ -0,0 +1,189 @@
+package vartiq
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// labelOp is the comparison made by a label requirement
+type labelOp int
+
+const (
+	labelEquals labelOp = iota
+	labelNotEquals
+	labelExists
+	labelNotExists
+)
+
+type labelRequirement struct {
+	key   string
+	op    labelOp
+	value string
+}
+
+func (r labelRequirement) matches(labels map[string]string) bool {
+	value, ok := labels[r.key]
+	switch r.op {
+	case labelEquals:
+		return ok && value == r.value
+	case labelNotEquals:
+		return !ok || value != r.value
+	case labelExists:
+		return ok
+	default:
+		return !ok
+	}
+}
+
+func (r labelRequirement) String() string {
+	switch r.op {
+	case labelEquals:
+		return r.key + "=" + r.value
+	case labelNotEquals:
+		return r.key + "!=" + r.value
+	case labelExists:
+		return r.key
+	default:
+		return "!" + r.key
+	}
+}
+
+// LabelSelector selects resources by their metadata. It is a comma-separated
+// list of requirements that must all hold:
+//
+//	team=payments    team is payments
+//	env!=prod        env is not prod, or is not set
+//	customer         customer is set
+//	!deprecated      deprecated is not set
+type LabelSelector struct {
+	requirements []labelRequirement
+}
+
+// ParseLabelSelector parses a label selector such as "team=payments,env!=prod"
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	var s LabelSelector
+	for _, part := range strings.Split(selector, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		var r labelRequirement
+		switch {
+		case strings.Contains(part, "!="):
+			r.key, r.value, _ = strings.Cut(part, "!=")
+			r.op = labelNotEquals
+		case strings.Contains(part, "="):
+			r.key, r.value, _ = strings.Cut(part, "=")
+			r.value = strings.TrimPrefix(r.value, "=")
+			r.op = labelEquals
+		case strings.HasPrefix(part, "!"):
+			r.key = part[1:]
+			r.op = labelNotExists
+		default:
+			r.key = part
+			r.op = labelExists
+		}
+		r.key = strings.TrimSpace(r.key)
+		r.value = strings.TrimSpace(r.value)
+		if r.key == "" || strings.ContainsAny(r.key, "=! ") || strings.ContainsAny(r.value, "=!") {
+			return LabelSelector{}, fmt.Errorf("vartiq: invalid label selector %q", part)
+		}
+		s.requirements = append(s.requirements, r)
+	}
+	return s, nil
+}
+
+// SelectLabels returns a selector that matches resources with all of labels
+func SelectLabels(labels map[string]string) LabelSelector {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var s LabelSelector
+	for _, k := range keys {
+		s.requirements = append(s.requirements, labelRequirement{key: k, op: labelEquals, value: labels[k]})
+	}
+	return s
+}
+
+// Empty reports whether the selector matches every resource
+func (s LabelSelector) Empty() bool { return len(s.requirements) == 0 }
+
+// Matches reports whether labels satisfy every requirement of the selector
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, r := range s.requirements {
+		if !r.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s LabelSelector) String() string {
+	parts := make([]string, len(s.requirements))
+	for i, r := range s.requirements {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// ListOption configures the list methods of the services
+type ListOption func(*listOptions)
+
+type listOptions struct {
+	selector LabelSelector
+	err      error
+}
+
+// WithLabelSelector lists only resources whose metadata matches selector.
+// See LabelSelector for the syntax.
+func WithLabelSelector(selector string) ListOption {
+	return func(o *listOptions) {
+		s, err := ParseLabelSelector(selector)
+		if err != nil && o.err == nil {
+			o.err = err
+		}
+		o.selector.requirements = append(o.selector.requirements, s.requirements...)
+	}
+}
+
+// WithLabels lists only resources whose metadata contains all of labels
+func WithLabels(labels map[string]string) ListOption {
+	return func(o *listOptions) {
+		o.selector.requirements = append(o.selector.requirements, SelectLabels(labels).requirements...)
+	}
+}
+
+func newListOptions(opts []ListOption) (listOptions, error) {
+	var o listOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o, o.err
+}
+
+// queryParams returns the query parameters that ask the API to filter
+func (o listOptions) queryParams() map[string]string {
+	if o.selector.Empty() {
+		return nil
+	}
+	return map[string]string{"labelSelector": o.selector.String()}
+}
+
+// filterLabels drops the items whose metadata does not match the selector.
+// The API filters by labelSelector too; filtering again keeps results
+// correct against servers that ignore the parameter.
+func filterLabels[T any](items []T, s LabelSelector, metadata func(T) map[string]string) []T {
+	if s.Empty() {
+		return items
+	}
+	out := items[:0]
+	for _, item := range items {
+		if s.Matches(metadata(item)) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+package vartiq
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/internal/apitest"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	s, err := ParseLabelSelector("team=payments, env!=prod,customer,!deprecated,tier==gold")
+	require.NoError(t, err)
+	assert.Equal(t, "team=payments,env!=prod,customer,!deprecated,tier=gold", s.String())
+
+	assert.True(t, s.Matches(map[string]string{"team": "payments", "customer": "42", "tier": "gold"}))
+	assert.True(t, s.Matches(map[string]string{"team": "payments", "customer": "42", "tier": "gold", "env": "dev"}))
+	assert.False(t, s.Matches(map[string]string{"team": "payments", "customer": "42", "tier": "gold", "env": "prod"}))
+	assert.False(t, s.Matches(map[string]string{"team": "payments", "customer": "42", "tier": "gold", "deprecated": ""}))
+	assert.False(t, s.Matches(map[string]string{"team": "payments", "tier": "gold"}))
+	assert.False(t, s.Matches(nil))
+
+	empty, err := ParseLabelSelector("")
+	require.NoError(t, err)
+	assert.True(t, empty.Empty())
+	assert.True(t, empty.Matches(nil))
+
+	for _, bad := range []string{"=x", "a=b=c", "!", "a b", "a!=!b"} {
+		_, err := ParseLabelSelector(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestSelectLabels(t *testing.T) {
+	s := SelectLabels(map[string]string{"env": "prod", "team": "payments"})
+	assert.Equal(t, "env=prod,team=payments", s.String())
+}
+
+func TestList_LabelSelector(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	ctx := context.Background()
+
+	payments := api.Add(apitest.Projects, apitest.Object{"name": "payments", "metadata": map[string]interface{}{"team": "payments", "env": "prod"}})
+	api.Add(apitest.Projects, apitest.Object{"name": "search", "metadata": map[string]interface{}{"team": "search"}})
+	api.Add(apitest.Projects, apitest.Object{"name": "legacy"})
+
+	projects, err := client.Project.List(ctx, WithLabelSelector("team"))
+	require.NoError(t, err)
+	assert.Len(t, projects.Data, 2)
+
+	projects, err = client.Project.List(ctx, WithLabels(map[string]string{"team": "payments"}), WithLabelSelector("env=prod"))
+	require.NoError(t, err)
+	require.Len(t, projects.Data, 1)
+	assert.Equal(t, map[string]string{"team": "payments", "env": "prod"}, projects.Data[0].Metadata)
+
+	app, err := client.App.Create(ctx, &CreateAppRequest{Name: "orders", ProjectID: payments, Metadata: map[string]string{"customer": "42"}})
+	require.NoError(t, err)
+	api.Add(apitest.Apps, apitest.Object{"name": "billing", "projectId": payments})
+	apps, err := client.App.List(ctx, payments, WithLabelSelector("customer=42"))
+	require.NoError(t, err)
+	require.Len(t, apps.Data, 1)
+	assert.Equal(t, app.Data.ID, apps.Data[0].ID)
+
+	_, err = client.Webhook.Create(ctx, &CreateWebhookRequest{Name: "a", URL: "https://a", AppID: app.Data.ID, Metadata: map[string]string{"env": "prod"}})
+	require.NoError(t, err)
+	_, err = client.Webhook.Create(ctx, &CreateWebhookRequest{Name: "b", URL: "https://b", AppID: app.Data.ID})
+	require.NoError(t, err)
+	webhooks, err := client.Webhook.GetAll(ctx, app.Data.ID, WithLabelSelector("!env"))
+	require.NoError(t, err)
+	require.Len(t, webhooks.Data, 1)
+	assert.Equal(t, "b", webhooks.Data[0].Name)
+
+	_, err = client.Project.List(ctx, WithLabelSelector("a=b=c"))
+	assert.Error(t, err)
+}
+
+func TestList_SendsLabelSelector(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("labelSelector")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success":true,"data":[]}`))
+	}))
+	defer server.Close()
+	client := New("key", server.URL)
+
+	_, err := client.App.List(context.Background(), "p1", WithLabelSelector("team=payments"))
+	require.NoError(t, err)
+	assert.Equal(t, "team=payments", query)
+
+	_, err = client.Webhook.GetAll(context.Background(), "a1")
+	require.NoError(t, err)
+	assert.Empty(t, query)
+}
+
+func TestUpdate_ClearsMetadata(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	ctx := context.Background()
+	projectID := api.Add(apitest.Projects, apitest.Object{"name": "payments", "metadata": map[string]interface{}{"team": "payments"}})
+	appID := api.Add(apitest.Apps, apitest.Object{"name": "orders", "projectId": projectID, "metadata": map[string]interface{}{"customer": "42"}})
+
+	// a nil map leaves the labels alone
+	_, err := client.Project.Update(ctx, projectID, &UpdateProjectRequest{Description: "Payments"})
+	require.NoError(t, err)
+	project, _ := api.Get(apitest.Projects, projectID)
+	assert.NotEmpty(t, project["metadata"])
+
+	_, err = client.Project.Update(ctx, projectID, &UpdateProjectRequest{Metadata: map[string]string{}})
+	require.NoError(t, err)
+	project, _ = api.Get(apitest.Projects, projectID)
+	assert.Empty(t, project["metadata"])
+
+	_, err = client.App.Update(ctx, appID, &UpdateAppRequest{Metadata: map[string]string{}})
+	require.NoError(t, err)
+	app, _ := api.Get(apitest.Apps, appID)
+	assert.Empty(t, app["metadata"])
+}
@@ -0,0 +1,43 @@
+package vartiq
+
+import (
+	"net/http"
+	"time"
+)
+
+// Metrics receives measurements from a Client. Implementations must be safe
+// for concurrent use. The metrics package provides a Prometheus collector.
+type Metrics interface {
+	// ObserveRequest is called once per API call. status is 0 when the
+	// request failed before a response was received.
+	ObserveRequest(operation string, status int, duration time.Duration, err error)
+	// ObserveRetry is called each time the client retries a request
+	ObserveRetry(operation string)
+	// ObserveMessageSent is called after a webhook message is accepted
+	ObserveMessageSent(appID string)
+	// ObserveVerification is called with the result of each Verify call
+	ObserveVerification(ok bool)
+}
+
+// WithMetrics reports client measurements to m
+func WithMetrics(m Metrics) Option {
+	return func(c *Client) {
+		c.metrics = m
+	}
+}
+
+// metricsMiddleware reports the outcome and latency of every API call
+func metricsMiddleware(m Metrics) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+			status := 0
+			if resp != nil {
+				status = resp.StatusCode
+			}
+			m.ObserveRequest(Operation(req.Context()), status, time.Since(start), err)
+			return resp, err
+		}
+	}
+}
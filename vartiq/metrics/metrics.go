@@ -0,0 +1,244 @@
+// Package metrics provides a vartiq.Metrics collector that exposes client
+// measurements in the Prometheus text exposition format without depending on
+// the Prometheus client library.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+// DefaultBuckets are the request duration histogram buckets in seconds
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var _ vartiq.Metrics = (*Collector)(nil)
+
+// Option configures a Collector
+type Option func(*Collector)
+
+// WithNamespace sets the prefix of all metric names. It defaults to "vartiq".
+func WithNamespace(namespace string) Option {
+	return func(c *Collector) {
+		c.namespace = namespace
+	}
+}
+
+// WithBuckets sets the request duration histogram buckets in seconds. The
+// +Inf bucket is always exported and need not be listed.
+func WithBuckets(buckets []float64) Option {
+	return func(c *Collector) {
+		var upper []float64
+		for _, le := range buckets {
+			if !math.IsInf(le, 1) && !math.IsNaN(le) {
+				upper = append(upper, le)
+			}
+		}
+		sort.Float64s(upper)
+		upper = slices.Compact(upper)
+		if len(upper) > 0 {
+			c.buckets = upper
+		}
+	}
+}
+
+type requestKey struct {
+	operation string
+	status    string
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Collector records vartiq.Client measurements in memory. Register it with
+// vartiq.WithMetrics and serve Handler on your metrics endpoint.
+type Collector struct {
+	namespace string
+	buckets   []float64
+
+	mu            sync.Mutex
+	requests      map[requestKey]uint64
+	durations     map[string]*histogram
+	retries       map[string]uint64
+	messagesSent  uint64
+	verifications map[bool]uint64
+}
+
+// NewCollector creates an empty Collector
+func NewCollector(opts ...Option) *Collector {
+	c := &Collector{
+		namespace:     "vartiq",
+		buckets:       DefaultBuckets,
+		requests:      make(map[requestKey]uint64),
+		durations:     make(map[string]*histogram),
+		retries:       make(map[string]uint64),
+		verifications: make(map[bool]uint64),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *Collector) ObserveRequest(operation string, status int, duration time.Duration, err error) {
+	statusLabel := "error"
+	if status > 0 {
+		statusLabel = strconv.Itoa(status)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests[requestKey{operation: operation, status: statusLabel}]++
+
+	h, ok := c.durations[operation]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(c.buckets))}
+		c.durations[operation] = h
+	}
+	seconds := duration.Seconds()
+	for i, le := range c.buckets {
+		if seconds <= le {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+func (c *Collector) ObserveRetry(operation string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.retries[operation]++
+}
+
+func (c *Collector) ObserveMessageSent(appID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messagesSent++
+}
+
+func (c *Collector) ObserveVerification(ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.verifications[ok]++
+}
+
+// Handler serves the collected metrics in the Prometheus text format
+func (c *Collector) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.WriteTo(w)
+	})
+}
+
+// WriteTo writes the collected metrics in the Prometheus text format
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	ns := c.namespace
+
+	name := ns + "_requests_total"
+	header(cw, name, "counter", "Vartiq API requests by operation and status.")
+	keys := make([]requestKey, 0, len(c.requests))
+	for k := range c.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].operation != keys[j].operation {
+			return keys[i].operation < keys[j].operation
+		}
+		return keys[i].status < keys[j].status
+	})
+	for _, k := range keys {
+		fmt.Fprintf(cw, "%s{operation=%s,status=%s} %d\n", name, quote(k.operation), quote(k.status), c.requests[k])
+	}
+
+	name = ns + "_request_duration_seconds"
+	header(cw, name, "histogram", "Vartiq API request latency by operation.")
+	for _, op := range sortedKeys(c.durations) {
+		h := c.durations[op]
+		for i, le := range c.buckets {
+			fmt.Fprintf(cw, "%s_bucket{operation=%s,le=%s} %d\n", name, quote(op), quote(formatFloat(le)), h.counts[i])
+		}
+		fmt.Fprintf(cw, "%s_bucket{operation=%s,le=\"+Inf\"} %d\n", name, quote(op), h.count)
+		fmt.Fprintf(cw, "%s_sum{operation=%s} %s\n", name, quote(op), formatFloat(h.sum))
+		fmt.Fprintf(cw, "%s_count{operation=%s} %d\n", name, quote(op), h.count)
+	}
+
+	name = ns + "_retries_total"
+	header(cw, name, "counter", "Vartiq API request retries by operation.")
+	for _, op := range sortedKeys(c.retries) {
+		fmt.Fprintf(cw, "%s{operation=%s} %d\n", name, quote(op), c.retries[op])
+	}
+
+	name = ns + "_messages_sent_total"
+	header(cw, name, "counter", "Webhook messages accepted by the Vartiq API.")
+	fmt.Fprintf(cw, "%s %d\n", name, c.messagesSent)
+
+	name = ns + "_verifications_total"
+	header(cw, name, "counter", "Webhook signature verifications by result.")
+	fmt.Fprintf(cw, "%s{result=\"failure\"} %d\n", name, c.verifications[false])
+	fmt.Fprintf(cw, "%s{result=\"success\"} %d\n", name, c.verifications[true])
+
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+	return cw.n, cw.w.Flush()
+}
+
+func header(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// quote formats a label value, escaping backslashes, quotes and newlines
+func quote(v string) string {
+	v = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+	return `"` + v + `"`
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+func TestCollector_WriteTo(t *testing.T) {
+	c := NewCollector(WithBuckets([]float64{0.1, 1}))
+	c.ObserveRequest("app.get", 200, 50*time.Millisecond, nil)
+	c.ObserveRequest("app.get", 200, 500*time.Millisecond, nil)
+	c.ObserveRequest("app.get", 0, time.Second*2, errors.New("boom"))
+	c.ObserveRetry("app.get")
+	c.ObserveMessageSent("app-1")
+	c.ObserveVerification(true)
+	c.ObserveVerification(false)
+	c.ObserveVerification(true)
+
+	var b strings.Builder
+	_, err := c.WriteTo(&b)
+	require.NoError(t, err)
+	out := b.String()
+
+	for _, line := range []string{
+		"# TYPE vartiq_requests_total counter",
+		`vartiq_requests_total{operation="app.get",status="200"} 2`,
+		`vartiq_requests_total{operation="app.get",status="error"} 1`,
+		"# TYPE vartiq_request_duration_seconds histogram",
+		`vartiq_request_duration_seconds_bucket{operation="app.get",le="0.1"} 1`,
+		`vartiq_request_duration_seconds_bucket{operation="app.get",le="1"} 2`,
+		`vartiq_request_duration_seconds_bucket{operation="app.get",le="+Inf"} 3`,
+		`vartiq_request_duration_seconds_sum{operation="app.get"} 2.55`,
+		`vartiq_request_duration_seconds_count{operation="app.get"} 3`,
+		`vartiq_retries_total{operation="app.get"} 1`,
+		"vartiq_messages_sent_total 1",
+		`vartiq_verifications_total{result="failure"} 1`,
+		`vartiq_verifications_total{result="success"} 2`,
+	} {
+		assert.Contains(t, out, line+"\n")
+	}
+}
+
+func TestCollector_ExpositionFormat(t *testing.T) {
+	for _, buckets := range [][]float64{nil, {1, 0.1, math.Inf(1), 1}} {
+		c := NewCollector(WithBuckets(buckets))
+		c.ObserveRequest("app.get", 200, 50*time.Millisecond, nil)
+		c.ObserveRequest("app.list", 200, 20*time.Second, nil)
+
+		var b strings.Builder
+		_, err := c.WriteTo(&b)
+		require.NoError(t, err)
+
+		types := map[string]bool{}
+		bounds := map[string][]string{}
+		for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n") {
+			if name, ok := strings.CutPrefix(line, "# TYPE "); ok {
+				name = strings.Fields(name)[0]
+				assert.False(t, types[name], "duplicate TYPE for %s", name)
+				types[name] = true
+				continue
+			}
+			if strings.HasPrefix(line, "#") || !strings.Contains(line, "_bucket{") {
+				continue
+			}
+			labels := line[strings.Index(line, "{")+1 : strings.Index(line, "}")]
+			op := strings.Split(labels, ",")[0]
+			le := strings.Trim(strings.TrimPrefix(strings.Split(labels, ",")[1], "le="), `"`)
+			bounds[op] = append(bounds[op], le)
+		}
+
+		require.Len(t, bounds, 2)
+		for op, les := range bounds {
+			assert.Equal(t, "+Inf", les[len(les)-1], op)
+			seen := map[string]bool{}
+			prev := math.Inf(-1)
+			for _, le := range les {
+				assert.False(t, seen[le], "duplicate bucket le=%s for %s", le, op)
+				seen[le] = true
+				f, err := strconv.ParseFloat(le, 64)
+				require.NoError(t, err)
+				assert.Greater(t, f, prev, op)
+				prev = f
+			}
+		}
+	}
+}
+
+func TestCollector_Namespace(t *testing.T) {
+	c := NewCollector(WithNamespace("myapp_vartiq"))
+	c.ObserveMessageSent("app-1")
+
+	var b strings.Builder
+	_, err := c.WriteTo(&b)
+	require.NoError(t, err)
+	assert.Contains(t, b.String(), "myapp_vartiq_messages_sent_total 1\n")
+}
+
+func TestQuote(t *testing.T) {
+	assert.Equal(t, `"a\"b\\c\nd"`, quote("a\"b\\c\nd"))
+}
+
+func TestCollector_WithClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"id":"project-1"},"success":true}`))
+	}))
+	defer server.Close()
+
+	c := NewCollector()
+	client := vartiq.NewWithOptions("test-key", vartiq.WithBaseURL(server.URL), vartiq.WithMetrics(c))
+	_, err := client.Project.Get(context.Background(), "project-1")
+	require.NoError(t, err)
+	_, _ = client.Verify([]byte("payload"), "00", "secret")
+
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `vartiq_requests_total{operation="project.get",status="200"} 1`)
+	assert.Contains(t, rec.Body.String(), `vartiq_verifications_total{result="failure"} 1`)
+}
@@ -0,0 +1,73 @@
+package vartiq
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingMetrics keeps every observation for assertions
+type recordingMetrics struct {
+	mu            sync.Mutex
+	requests      []string
+	retries       []string
+	sent          []string
+	verifications []bool
+}
+
+func (m *recordingMetrics) ObserveRequest(operation string, status int, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, operation+":"+http.StatusText(status))
+}
+
+func (m *recordingMetrics) ObserveRetry(operation string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries = append(m.retries, operation)
+}
+
+func (m *recordingMetrics) ObserveMessageSent(appID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, appID)
+}
+
+func (m *recordingMetrics) ObserveVerification(ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.verifications = append(m.verifications, ok)
+}
+
+func TestWithMetrics(t *testing.T) {
+	first := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if first {
+			first = false
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"webhookMessages":[{"id":"msg-1","payload":"{}"}]},"message":"ok","success":true}`))
+	}))
+	defer server.Close()
+
+	m := &recordingMetrics{}
+	client := NewWithOptions("test-key", WithBaseURL(server.URL), WithMetrics(m), WithRateLimit(100, 10))
+
+	_, err := client.WebhookMessage.Create(context.Background(), "app-1", map[string]interface{}{})
+	require.NoError(t, err)
+	_, _ = client.Verify([]byte("payload"), "", "secret")
+
+	assert.Equal(t, []string{"webhook_message.create:OK"}, m.requests)
+	assert.Equal(t, []string{"webhook_message.create"}, m.retries)
+	assert.Equal(t, []string{"app-1"}, m.sent)
+	assert.Equal(t, []bool{false}, m.verifications)
+}
@@ -0,0 +1,59 @@
+package vartiq
+
+import (
+	"net/http"
+)
+
+// RoundTripFunc sends an API request and returns its response
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps the RoundTripFunc that sends API requests. It may inspect
+// or modify the request before calling next, and inspect the response or
+// error afterwards. Use Operation(req.Context()) to find out which SDK
+// operation, such as "webhook.create", issued the request.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// WithMiddleware adds middleware to the client. Every service method flows
+// through the middleware chain; the first middleware is the outermost one.
+// Middleware runs before the circuit breaker and rate limiter, so it sees one
+// call per operation even when a rate-limited request is retried.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, mw...)
+	}
+}
+
+// roundTripFunc adapts a RoundTripFunc to http.RoundTripper
+type roundTripFunc RoundTripFunc
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// transport builds the round tripper that sends the client's requests
+func (c *Client) transport(base http.RoundTripper) http.RoundTripper {
+	if c.credentials != nil {
+		base = &credentialsTransport{client: c, base: base}
+	}
+	if c.rateLimiter != nil || len(c.serviceLimiters) > 0 {
+		base = &rateLimitTransport{client: c, base: base}
+	}
+	if c.retry != nil {
+		base = &retryTransport{client: c, cfg: *c.retry, base: base}
+	}
+	if c.breaker != nil {
+		base = &breakerTransport{breaker: c.breaker, base: base}
+	}
+	middleware := c.middleware
+	if c.metrics != nil {
+		middleware = append([]Middleware{metricsMiddleware(c.metrics)}, middleware...)
+	}
+	if len(middleware) == 0 {
+		return base
+	}
+	next := RoundTripFunc(base.RoundTrip)
+	for i := len(middleware) - 1; i >= 0; i-- {
+		next = middleware[i](next)
+	}
+	return roundTripFunc(next)
+}
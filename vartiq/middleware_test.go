@@ -0,0 +1,63 @@
+package vartiq
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	var gotHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Trace-ID")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"id":"webhook-1"},"success":true}`))
+	}))
+	defer server.Close()
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+":"+Operation(req.Context()))
+				return next(req)
+			}
+		}
+	}
+	addHeader := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("X-Trace-ID", "trace-123")
+			return next(req)
+		}
+	}
+
+	client := NewWithOptions("test-key",
+		WithBaseURL(server.URL),
+		WithMiddleware(record("outer"), record("inner")),
+		WithMiddleware(addHeader),
+	)
+
+	resp, err := client.Webhook.GetOne(context.Background(), "webhook-1")
+	require.NoError(t, err)
+	assert.Equal(t, "webhook-1", resp.Data.ID)
+	assert.Equal(t, []string{"outer:webhook.get", "inner:webhook.get"}, calls)
+	assert.Equal(t, "trace-123", gotHeader)
+}
+
+func TestWithMiddleware_ShortCircuit(t *testing.T) {
+	client := NewWithOptions("test-key",
+		WithBaseURL("http://127.0.0.1:0"),
+		WithMiddleware(func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				return nil, assert.AnError
+			}
+		}),
+	)
+
+	err := client.App.Delete(context.Background(), "app-1")
+	assert.ErrorIs(t, err, assert.AnError)
+}
@@ -0,0 +1,328 @@
+// Package otelvartiq instruments a vartiq.Client with OpenTelemetry. Every
+// service call emits a client span and request metrics, outgoing API
+// requests carry the trace context in their headers, and webhook
+// verification emits spans of its own.
+//
+// Messages sent with WebhookMessage.Create also carry the trace context as
+// message headers, so their deliveries arrive with a traceparent header and
+// a receiver's "webhook.verify" span joins the sender's trace.
+package otelvartiq
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+const scope = "github.com/vartiqhq/vartiq-go-sdk/vartiq/otelvartiq"
+
+// Attribute keys recorded on spans and metrics
+const (
+	OperationKey  = attribute.Key("vartiq.operation")
+	ProjectIDKey  = attribute.Key("vartiq.project_id")
+	AppIDKey      = attribute.Key("vartiq.app_id")
+	WebhookIDKey  = attribute.Key("vartiq.webhook_id")
+	RetryCountKey = attribute.Key("vartiq.retry_count")
+	ErrorClassKey = attribute.Key("vartiq.error_class")
+	StatusCodeKey = attribute.Key("http.response.status_code")
+)
+
+// Option configures an Instrumentation
+type Option func(*config)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagators    propagation.TextMapPropagator
+}
+
+// WithTracerProvider sets the tracer provider. The global provider is used by default.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the meter provider. The global provider is used by default.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithPropagators sets the propagators used to inject trace context into
+// outgoing requests. The global propagators are used by default.
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagators = p
+	}
+}
+
+// Instrumentation records spans and metrics for Vartiq API calls
+type Instrumentation struct {
+	tracer      trace.Tracer
+	propagators propagation.TextMapPropagator
+
+	duration      metric.Float64Histogram
+	errors        metric.Int64Counter
+	verifications metric.Int64Counter
+}
+
+// New creates an Instrumentation
+func New(opts ...Option) (*Instrumentation, error) {
+	cfg := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+		propagators:    otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	meter := cfg.meterProvider.Meter(scope)
+	duration, err := meter.Float64Histogram("vartiq.client.duration",
+		metric.WithDescription("Duration of Vartiq API calls"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	errorCount, err := meter.Int64Counter("vartiq.client.errors",
+		metric.WithDescription("Failed Vartiq API calls by error class"))
+	if err != nil {
+		return nil, err
+	}
+	verifications, err := meter.Int64Counter("vartiq.webhook.verifications",
+		metric.WithDescription("Webhook signature verifications by result"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Instrumentation{
+		tracer:        cfg.tracerProvider.Tracer(scope),
+		propagators:   cfg.propagators,
+		duration:      duration,
+		errors:        errorCount,
+		verifications: verifications,
+	}, nil
+}
+
+// NewClient creates a vartiq.Client whose calls are instrumented
+func (i *Instrumentation) NewClient(apiKey string, opts ...vartiq.Option) *vartiq.Client {
+	return vartiq.NewWithOptions(apiKey, append(opts, i.ClientOption())...)
+}
+
+// ClientOption returns a vartiq.Option that instruments a client
+func (i *Instrumentation) ClientOption() vartiq.Option {
+	return vartiq.WithMiddleware(i.Middleware())
+}
+
+// Middleware returns vartiq middleware that records a span and metrics for
+// each API call and injects the trace context into the headers of the API
+// request. For message sends it is also added to the message headers, which
+// are forwarded to the deliveries.
+func (i *Instrumentation) Middleware() vartiq.Middleware {
+	return func(next vartiq.RoundTripFunc) vartiq.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			operation := vartiq.Operation(req.Context())
+			if operation == "" {
+				operation = req.Method + " " + req.URL.Path
+			}
+			attrs := append([]attribute.KeyValue{OperationKey.String(operation)}, resourceAttributes(req)...)
+
+			ctx, span := i.tracer.Start(req.Context(), operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attrs...))
+			defer span.End()
+
+			req = req.WithContext(ctx)
+			i.propagators.Inject(ctx, propagation.HeaderCarrier(req.Header))
+			if operation == "webhook_message.create" {
+				i.injectMessageHeaders(ctx, req)
+			}
+
+			start := time.Now()
+			resp, err := next(req)
+			elapsed := time.Since(start).Seconds()
+
+			span.SetAttributes(RetryCountKey.Int(vartiq.RetryCount(ctx)))
+			metricAttrs := []attribute.KeyValue{OperationKey.String(operation)}
+			if resp != nil {
+				span.SetAttributes(StatusCodeKey.Int(resp.StatusCode))
+				metricAttrs = append(metricAttrs, StatusCodeKey.Int(resp.StatusCode))
+			}
+
+			if class := errorClass(resp, err); class != "" {
+				if err != nil {
+					span.RecordError(err)
+					span.SetStatus(codes.Error, err.Error())
+				} else {
+					span.SetStatus(codes.Error, resp.Status)
+				}
+				span.SetAttributes(ErrorClassKey.String(class))
+				i.errors.Add(ctx, 1, metric.WithAttributes(OperationKey.String(operation), ErrorClassKey.String(class)))
+			}
+			i.duration.Record(ctx, elapsed, metric.WithAttributes(metricAttrs...))
+			return resp, err
+		}
+	}
+}
+
+// Verify runs client.Verify inside a "webhook.verify" span. Pass the request
+// context of the incoming delivery, or the result of Extract when the
+// delivery carries trace headers.
+func (i *Instrumentation) Verify(ctx context.Context, client *vartiq.Client, payload []byte, signature, secret string) ([]byte, error) {
+	ctx, span := i.tracer.Start(ctx, "webhook.verify", trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	verified, err := client.Verify(payload, signature, secret)
+	result := "success"
+	if err != nil {
+		result = "failure"
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	i.verifications.Add(ctx, 1, metric.WithAttributes(attribute.String("vartiq.result", result)))
+	return verified, err
+}
+
+// Extract returns ctx with the trace context found in the headers of an
+// incoming webhook delivery. The headers are present when the message was
+// sent by an instrumented client.
+func (i *Instrumentation) Extract(ctx context.Context, header http.Header) context.Context {
+	return i.propagators.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// injectMessageHeaders adds the trace context to the headers in the JSON
+// body of a message send. Headers already set by the caller are kept. The
+// request is left unchanged if its body cannot be read.
+func (i *Instrumentation) injectMessageHeaders(ctx context.Context, req *http.Request) {
+	carrier := propagation.MapCarrier{}
+	i.propagators.Inject(ctx, carrier)
+	if len(carrier) == 0 || req.GetBody == nil {
+		return
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return
+	}
+	var fields map[string]json.RawMessage
+	err = json.NewDecoder(body).Decode(&fields)
+	body.Close()
+	if err != nil {
+		return
+	}
+	var headers []vartiq.Header
+	if raw, ok := fields["headers"]; ok {
+		if err := json.Unmarshal(raw, &headers); err != nil {
+			return
+		}
+	}
+	keys := carrier.Keys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !hasHeader(headers, key) {
+			headers = append(headers, vartiq.Header{Key: key, Value: carrier.Get(key)})
+		}
+	}
+	raw, err := json.Marshal(headers)
+	if err != nil {
+		return
+	}
+	fields["headers"] = raw
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return
+	}
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(b)), nil }
+	req.ContentLength = int64(len(b))
+}
+
+func hasHeader(headers []vartiq.Header, key string) bool {
+	for _, h := range headers {
+		if strings.EqualFold(h.Key, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// errorClass groups failed calls for the error counter. It returns "" for
+// successful calls.
+func errorClass(resp *http.Response, err error) string {
+	switch {
+	case errors.Is(err, vartiq.ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "canceled"
+	case err != nil:
+		return "network"
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return "rate_limited"
+	case resp.StatusCode >= 500:
+		return "server_error"
+	case resp.StatusCode >= 400:
+		return "client_error"
+	}
+	return ""
+}
+
+// resourceAttributes extracts resource IDs from the request path, query and,
+// for message sends, the JSON body
+func resourceAttributes(req *http.Request) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(segments) >= 2 && segments[1] != "" {
+		switch segments[0] {
+		case "projects":
+			attrs = append(attrs, ProjectIDKey.String(segments[1]))
+		case "apps":
+			attrs = append(attrs, AppIDKey.String(segments[1]))
+		case "webhooks":
+			attrs = append(attrs, WebhookIDKey.String(segments[1]))
+		}
+	}
+
+	query := req.URL.Query()
+	if id := query.Get("projectId"); id != "" {
+		attrs = append(attrs, ProjectIDKey.String(id))
+	}
+	if id := query.Get("appId"); id != "" {
+		attrs = append(attrs, AppIDKey.String(id))
+	}
+
+	if req.GetBody != nil && req.Method == http.MethodPost {
+		if body, err := req.GetBody(); err == nil {
+			var fields struct {
+				AppID     string `json:"appId"`
+				ProjectID string `json:"projectId"`
+			}
+			err := json.NewDecoder(io.LimitReader(body, 1<<20)).Decode(&fields)
+			body.Close()
+			if err == nil {
+				if fields.AppID != "" {
+					attrs = append(attrs, AppIDKey.String(fields.AppID))
+				}
+				if fields.ProjectID != "" {
+					attrs = append(attrs, ProjectIDKey.String(fields.ProjectID))
+				}
+			}
+		}
+	}
+	return attrs
+}
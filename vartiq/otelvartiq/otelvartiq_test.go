@@ -0,0 +1,283 @@
+package otelvartiq
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/vartiqhq/vartiq-go-sdk/internal/apitest"
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+// spanRecorder is a tracer provider that keeps every ended span, so the
+// tests do not need the OpenTelemetry SDK
+type spanRecorder struct {
+	tracenoop.TracerProvider
+	mu    sync.Mutex
+	ended []*testSpan
+}
+
+func (r *spanRecorder) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return testTracer{recorder: r}
+}
+
+func (r *spanRecorder) Ended() []*testSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*testSpan(nil), r.ended...)
+}
+
+type testTracer struct {
+	tracenoop.Tracer
+	recorder *spanRecorder
+}
+
+func (t testTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+	traceID := trace.SpanContextFromContext(ctx).TraceID()
+	if !traceID.IsValid() {
+		rand.Read(traceID[:])
+	}
+	var spanID trace.SpanID
+	rand.Read(spanID[:])
+	span := &testSpan{
+		recorder: t.recorder,
+		name:     name,
+		context: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled,
+		}),
+		attrs: make(map[attribute.Key]attribute.Value),
+	}
+	span.SetAttributes(cfg.Attributes()...)
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+type testSpan struct {
+	tracenoop.Span
+	recorder *spanRecorder
+	name     string
+	context  trace.SpanContext
+	attrs    map[attribute.Key]attribute.Value
+	status   codes.Code
+}
+
+func (s *testSpan) SpanContext() trace.SpanContext { return s.context }
+func (s *testSpan) IsRecording() bool              { return true }
+func (s *testSpan) SetStatus(code codes.Code, _ string) {
+	s.status = code
+}
+
+func (s *testSpan) SetAttributes(kv ...attribute.KeyValue) {
+	for _, a := range kv {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *testSpan) End(...trace.SpanEndOption) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	s.recorder.ended = append(s.recorder.ended, s)
+}
+
+// meterRecorder is a meter that sums counters and counts histogram records
+// by instrument name
+type meterRecorder struct {
+	metricnoop.Meter
+	mu     sync.Mutex
+	values map[string]int64
+}
+
+type meterProvider struct {
+	metricnoop.MeterProvider
+	meter *meterRecorder
+}
+
+func (p meterProvider) Meter(string, ...metric.MeterOption) metric.Meter { return p.meter }
+
+func (m *meterRecorder) Int64Counter(name string, _ ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return testCounter{meter: m, name: name}, nil
+}
+
+func (m *meterRecorder) Float64Histogram(name string, _ ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	return testHistogram{meter: m, name: name}, nil
+}
+
+func (m *meterRecorder) add(name string, n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[name] += n
+}
+
+func (m *meterRecorder) Value(name string) (int64, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.values[name]
+	return v, ok
+}
+
+type testCounter struct {
+	metricnoop.Int64Counter
+	meter *meterRecorder
+	name  string
+}
+
+func (c testCounter) Add(_ context.Context, n int64, _ ...metric.AddOption) {
+	c.meter.add(c.name, n)
+}
+
+type testHistogram struct {
+	metricnoop.Float64Histogram
+	meter *meterRecorder
+	name  string
+}
+
+func (h testHistogram) Record(context.Context, float64, ...metric.RecordOption) {
+	h.meter.add(h.name, 1)
+}
+
+func newTestInstrumentation(t *testing.T) (*Instrumentation, *spanRecorder, *meterRecorder) {
+	spans := &spanRecorder{}
+	meters := &meterRecorder{values: make(map[string]int64)}
+	inst, err := New(
+		WithTracerProvider(spans),
+		WithMeterProvider(meterProvider{meter: meters}),
+		WithPropagators(propagation.TraceContext{}),
+	)
+	require.NoError(t, err)
+	return inst, spans, meters
+}
+
+func TestMiddleware_RecordsSpanAndPropagates(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"id":"app-1"},"success":true}`))
+	}))
+	defer server.Close()
+
+	inst, spans, _ := newTestInstrumentation(t)
+	client := inst.NewClient("test-key", vartiq.WithBaseURL(server.URL))
+
+	_, err := client.App.Get(context.Background(), "app-1")
+	require.NoError(t, err)
+
+	ended := spans.Ended()
+	require.Len(t, ended, 1)
+	assert.Equal(t, "app.get", ended[0].name)
+	attrs := ended[0].attrs
+	assert.Equal(t, "app.get", attrs[OperationKey].AsString())
+	assert.Equal(t, "app-1", attrs[AppIDKey].AsString())
+	assert.Equal(t, int64(200), attrs[StatusCodeKey].AsInt64())
+	assert.Equal(t, int64(0), attrs[RetryCountKey].AsInt64())
+
+	assert.Contains(t, traceparent, ended[0].context.TraceID().String())
+}
+
+func TestMiddleware_PropagatesToDeliveries(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+
+	inst, spans, _ := newTestInstrumentation(t)
+	client := inst.NewClient("test-key", vartiq.WithBaseURL(api.URL))
+	ctx := context.Background()
+
+	_, err := client.WebhookMessage.Create(ctx, "app-1", map[string]interface{}{"order": "42"})
+	require.NoError(t, err)
+	_, err = client.WebhookMessage.CreateWithOptions(ctx, "app-1", map[string]interface{}{},
+		vartiq.WithMessageHeaders(vartiq.Header{Key: "X-Request-Id", Value: "r-1"}, vartiq.Header{Key: "Traceparent", Value: "mine"}))
+	require.NoError(t, err)
+
+	ended := spans.Ended()
+	require.Len(t, ended, 2)
+	messages := api.Messages()
+	require.Len(t, messages, 2)
+	assert.Equal(t, `{"order":"42"}`, messages[0]["payload"])
+	headers := messages[0]["headers"].([]interface{})
+	require.Len(t, headers, 1)
+	header := headers[0].(map[string]interface{})
+	assert.Equal(t, "traceparent", header["key"])
+	assert.Contains(t, header["value"], ended[0].context.TraceID().String())
+
+	// Headers set by the caller win
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"key": "X-Request-Id", "value": "r-1"},
+		map[string]interface{}{"key": "Traceparent", "value": "mine"},
+	}, messages[1]["headers"])
+}
+
+func TestMiddleware_RecordsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	inst, spans, meters := newTestInstrumentation(t)
+	client := inst.NewClient("test-key", vartiq.WithBaseURL(server.URL))
+
+	_, _ = client.WebhookMessage.Create(context.Background(), "app-42", map[string]string{"a": "b"})
+
+	ended := spans.Ended()
+	require.Len(t, ended, 1)
+	assert.Equal(t, codes.Error, ended[0].status)
+	attrs := ended[0].attrs
+	assert.Equal(t, "app-42", attrs[AppIDKey].AsString())
+	assert.Equal(t, "server_error", attrs[ErrorClassKey].AsString())
+
+	errorCount, ok := meters.Value("vartiq.client.errors")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), errorCount)
+	_, ok = meters.Value("vartiq.client.duration")
+	assert.True(t, ok)
+}
+
+func TestVerify_RecordsSpan(t *testing.T) {
+	inst, spans, _ := newTestInstrumentation(t)
+	client := vartiq.New("test-key")
+
+	payload := []byte(`{"hello":"world"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(payload)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	ctx := inst.Extract(context.Background(), http.Header{
+		"Traceparent": []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	})
+	_, err := inst.Verify(ctx, client, payload, signature, "secret")
+	require.NoError(t, err)
+	_, err = inst.Verify(ctx, client, payload, signature, "wrong")
+	require.Error(t, err)
+
+	ended := spans.Ended()
+	require.Len(t, ended, 2)
+	assert.Equal(t, "webhook.verify", ended[0].name)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", ended[0].context.TraceID().String())
+	assert.Equal(t, codes.Unset, ended[0].status)
+	assert.Equal(t, codes.Error, ended[1].status)
+}
+
+func TestErrorClass(t *testing.T) {
+	assert.Equal(t, "", errorClass(&http.Response{StatusCode: 200}, nil))
+	assert.Equal(t, "client_error", errorClass(&http.Response{StatusCode: 404}, nil))
+	assert.Equal(t, "rate_limited", errorClass(&http.Response{StatusCode: 429}, nil))
+	assert.Equal(t, "server_error", errorClass(&http.Response{StatusCode: 503}, nil))
+	assert.Equal(t, "circuit_open", errorClass(nil, vartiq.ErrCircuitOpen))
+	assert.Equal(t, "canceled", errorClass(nil, context.Canceled))
+	assert.Equal(t, "network", errorClass(nil, assert.AnError))
+}
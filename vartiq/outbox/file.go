@@ -0,0 +1,303 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	opAppend = "append"
+	opSent   = "sent"
+)
+
+// logRecord is a single line of the append-only log
+type logRecord struct {
+	Op        string          `json:"op"`
+	Seq       uint64          `json:"seq"`
+	AppID     string          `json:"appId,omitempty"`
+	EventType string          `json:"eventType,omitempty"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	CreatedAt time.Time       `json:"createdAt,omitempty"`
+	MessageID string          `json:"messageId,omitempty"`
+}
+
+// FileStore is a Store backed by an append-only log file. Every append and
+// every sent marker is written as one JSON line and synced to disk, and the
+// pending set is rebuilt from the log when the store is reopened.
+type FileStore struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	nextSeq uint64
+	pending map[uint64]Message
+}
+
+// OpenFileStore opens the log at path, creating it if it does not exist, and
+// replays it to recover messages that were not marked sent before a restart.
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		path:    path,
+		nextSeq: 1,
+		pending: make(map[uint64]Message),
+	}
+	if err := s.replay(); err != nil {
+		return nil, err
+	}
+	if err := truncateTornTail(path); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: failed to open log: %w", err)
+	}
+	s.file = f
+	return s, nil
+}
+
+func (s *FileStore) replay() error {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("outbox: failed to open log: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			// A line without a newline was torn by a crash while it was
+			// written; OpenFileStore cuts it off
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("outbox: failed to read log: %w", err)
+		}
+		var rec logRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("outbox: corrupt log %s at line %d: %w", s.path, n, err)
+		}
+		switch rec.Op {
+		case opAppend:
+			s.pending[rec.Seq] = Message{
+				Seq:       rec.Seq,
+				AppID:     rec.AppID,
+				EventType: rec.EventType,
+				Payload:   rec.Payload,
+				CreatedAt: rec.CreatedAt,
+			}
+			if rec.Seq >= s.nextSeq {
+				s.nextSeq = rec.Seq + 1
+			}
+		case opSent:
+			delete(s.pending, rec.Seq)
+		}
+	}
+}
+
+// truncateTornTail cuts the log after its last complete line. Otherwise the
+// next append would be written onto the end of a line torn by a crash, and
+// both would be lost on the following replay.
+func truncateTornTail(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("outbox: failed to open log: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("outbox: failed to open log: %w", err)
+	}
+
+	end := info.Size()
+	buf := make([]byte, 4096)
+	for end > 0 {
+		n := int64(len(buf))
+		if n > end {
+			n = end
+		}
+		if _, err := f.ReadAt(buf[:n], end-n); err != nil {
+			return fmt.Errorf("outbox: failed to read log: %w", err)
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			end = end - n + int64(i) + 1
+			break
+		}
+		end -= n
+	}
+	if end == info.Size() {
+		return nil
+	}
+	if err := f.Truncate(end); err != nil {
+		return fmt.Errorf("outbox: failed to repair log: %w", err)
+	}
+	return f.Sync()
+}
+
+func (s *FileStore) write(rec logRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("outbox: failed to write log: %w", err)
+	}
+	return s.file.Sync()
+}
+
+func (s *FileStore) Append(ctx context.Context, appID string, payload json.RawMessage) (Message, error) {
+	return s.AppendMessage(ctx, Message{AppID: appID, Payload: payload})
+}
+
+func (s *FileStore) AppendMessage(ctx context.Context, msg Message) (Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return Message{}, ErrClosed
+	}
+	msg = Message{
+		Seq:       s.nextSeq,
+		AppID:     msg.AppID,
+		EventType: msg.EventType,
+		Payload:   append(json.RawMessage(nil), msg.Payload...),
+		CreatedAt: time.Now().UTC(),
+	}
+	err := s.write(logRecord{
+		Op:        opAppend,
+		Seq:       msg.Seq,
+		AppID:     msg.AppID,
+		EventType: msg.EventType,
+		Payload:   msg.Payload,
+		CreatedAt: msg.CreatedAt,
+	})
+	if err != nil {
+		return Message{}, err
+	}
+	s.nextSeq++
+	s.pending[msg.Seq] = msg
+	return msg, nil
+}
+
+func (s *FileStore) Pending(ctx context.Context, limit int) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil, ErrClosed
+	}
+	out := make([]Message, 0, len(s.pending))
+	for _, msg := range s.pending {
+		out = append(out, msg)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Seq < out[j].Seq })
+	if limit > 0 && limit < len(out) {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (s *FileStore) MarkSent(ctx context.Context, seq uint64, messageID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return ErrClosed
+	}
+	if _, ok := s.pending[seq]; !ok {
+		return ErrNotFound
+	}
+	if err := s.write(logRecord{Op: opSent, Seq: seq, MessageID: messageID}); err != nil {
+		return err
+	}
+	delete(s.pending, seq)
+	return nil
+}
+
+// Compact rewrites the log so it only contains pending messages. The new log
+// is written to a temporary file and renamed over the old one.
+func (s *FileStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return ErrClosed
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".compact-*")
+	if err != nil {
+		return fmt.Errorf("outbox: failed to compact log: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	seqs := make([]uint64, 0, len(s.pending))
+	for seq := range s.pending {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, seq := range seqs {
+		msg := s.pending[seq]
+		err := enc.Encode(logRecord{
+			Op:        opAppend,
+			Seq:       msg.Seq,
+			AppID:     msg.AppID,
+			EventType: msg.EventType,
+			Payload:   msg.Payload,
+			CreatedAt: msg.CreatedAt,
+		})
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	renameErr := os.Rename(tmp.Name(), s.path)
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		s.file = nil
+		return fmt.Errorf("outbox: failed to reopen log: %w", err)
+	}
+	s.file = f
+	if renameErr != nil {
+		return fmt.Errorf("outbox: failed to compact log: %w", renameErr)
+	}
+	return nil
+}
+
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
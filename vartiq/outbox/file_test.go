@@ -0,0 +1,153 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore_ResumesAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.log")
+	ctx := context.Background()
+
+	store, err := OpenFileStore(path)
+	require.NoError(t, err)
+	first, err := store.Append(ctx, "app-1", json.RawMessage(`{"n":1}`))
+	require.NoError(t, err)
+	_, err = store.AppendMessage(ctx, Message{AppID: "app-1", EventType: "order.created", Payload: json.RawMessage(`{"n":2}`)})
+	require.NoError(t, err)
+	require.NoError(t, store.MarkSent(ctx, first.Seq, "msg-1"))
+	require.NoError(t, store.Close())
+
+	store, err = OpenFileStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	pending, err := store.Pending(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, uint64(2), pending[0].Seq)
+	assert.Equal(t, "order.created", pending[0].EventType)
+	assert.JSONEq(t, `{"n":2}`, string(pending[0].Payload))
+
+	third, err := store.Append(ctx, "app-1", json.RawMessage(`{"n":3}`))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), third.Seq)
+}
+
+func TestFileStore_IgnoresTornWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.log")
+	ctx := context.Background()
+
+	store, err := OpenFileStore(path)
+	require.NoError(t, err)
+	_, err = store.Append(ctx, "app-1", json.RawMessage(`"ok"`))
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"append","seq":2,"appId":"app-1","payl`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	store, err = OpenFileStore(path)
+	require.NoError(t, err)
+	pending, err := store.Pending(ctx, 0)
+	require.NoError(t, err)
+	assert.Len(t, pending, 1)
+
+	// a message appended after the tear must survive the next reopen
+	_, err = store.Append(ctx, "app-1", json.RawMessage(`"after"`))
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	store, err = OpenFileStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+	pending, err = store.Pending(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.JSONEq(t, `"after"`, string(pending[1].Payload))
+}
+
+func TestFileStore_RejectsCorruptLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.log")
+	ctx := context.Background()
+
+	store, err := OpenFileStore(path)
+	require.NoError(t, err)
+	_, err = store.Append(ctx, "app-1", json.RawMessage(`"ok"`))
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	b = append([]byte("{garbage\n"), b...)
+	require.NoError(t, os.WriteFile(path, b, 0o600))
+
+	_, err = OpenFileStore(path)
+	assert.ErrorContains(t, err, "corrupt log")
+	assert.ErrorContains(t, err, "line 1")
+}
+
+func TestFileStore_LargePayload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.log")
+	ctx := context.Background()
+
+	store, err := OpenFileStore(path)
+	require.NoError(t, err)
+	payload := json.RawMessage(`"` + strings.Repeat("x", 20<<20) + `"`)
+	_, err = store.Append(ctx, "app-1", payload)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	store, err = OpenFileStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+	pending, err := store.Pending(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, len(payload), len(pending[0].Payload))
+}
+
+func TestFileStore_Compact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.log")
+	ctx := context.Background()
+
+	store, err := OpenFileStore(path)
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		msg, err := store.Append(ctx, "app-1", json.RawMessage(`{}`))
+		require.NoError(t, err)
+		if i < 9 {
+			require.NoError(t, store.MarkSent(ctx, msg.Seq, "msg"))
+		}
+	}
+	before, err := os.Stat(path)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Compact())
+	after, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Less(t, after.Size(), before.Size())
+
+	_, err = store.Append(ctx, "app-1", json.RawMessage(`{}`))
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	store, err = OpenFileStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+	pending, err := store.Pending(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, uint64(10), pending[0].Seq)
+	assert.Equal(t, uint64(11), pending[1].Seq)
+}
@@ -0,0 +1,81 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-memory Store. Pending messages do not survive a
+// restart, so it is mainly useful for tests and short-lived processes.
+type MemoryStore struct {
+	mu      sync.Mutex
+	nextSeq uint64
+	pending []Message
+	closed  bool
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{nextSeq: 1}
+}
+
+func (s *MemoryStore) Append(ctx context.Context, appID string, payload json.RawMessage) (Message, error) {
+	return s.AppendMessage(ctx, Message{AppID: appID, Payload: payload})
+}
+
+func (s *MemoryStore) AppendMessage(ctx context.Context, msg Message) (Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return Message{}, ErrClosed
+	}
+	msg = Message{
+		Seq:       s.nextSeq,
+		AppID:     msg.AppID,
+		EventType: msg.EventType,
+		Payload:   append(json.RawMessage(nil), msg.Payload...),
+		CreatedAt: time.Now().UTC(),
+	}
+	s.nextSeq++
+	s.pending = append(s.pending, msg)
+	return msg, nil
+}
+
+func (s *MemoryStore) Pending(ctx context.Context, limit int) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, ErrClosed
+	}
+	n := len(s.pending)
+	if limit > 0 && limit < n {
+		n = limit
+	}
+	out := make([]Message, n)
+	copy(out, s.pending[:n])
+	return out, nil
+}
+
+func (s *MemoryStore) MarkSent(ctx context.Context, seq uint64, messageID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	for i, msg := range s.pending {
+		if msg.Seq == seq {
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (s *MemoryStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
@@ -0,0 +1,277 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+// Sender publishes a message to the Vartiq API.
+// *vartiq.WebhookMessageService implements this interface.
+type Sender interface {
+	Create(ctx context.Context, appID string, payload interface{}) (*vartiq.WebhookMessageResponse, error)
+}
+
+// OptionSender is a Sender that also publishes message options such as event
+// types. *vartiq.WebhookMessageService implements this interface; messages
+// with an event type can only be published through one.
+type OptionSender interface {
+	Sender
+	CreateWithOptions(ctx context.Context, appID string, payload interface{}, opts ...vartiq.MessageOption) (*vartiq.WebhookMessageResponse, error)
+}
+
+// ErrEventTypeUnsupported is returned when a message has an event type but
+// the Sender or Store cannot handle one
+var ErrEventTypeUnsupported = errors.New("outbox: event types are not supported by this sender or store")
+
+// Option configures an Outbox
+type Option func(*Outbox)
+
+// WithBatchSize sets how many pending messages are read from the store at once
+func WithBatchSize(n int) Option {
+	return func(o *Outbox) {
+		if n > 0 {
+			o.batchSize = n
+		}
+	}
+}
+
+// WithPollInterval sets how often the store is checked for pending messages
+// when nothing new has been enqueued
+func WithPollInterval(d time.Duration) Option {
+	return func(o *Outbox) {
+		if d > 0 {
+			o.pollInterval = d
+		}
+	}
+}
+
+// WithBackoff sets the delay range used after a failed publish attempt
+func WithBackoff(min, max time.Duration) Option {
+	return func(o *Outbox) {
+		if min > 0 && max >= min {
+			o.minBackoff = min
+			o.maxBackoff = max
+		}
+	}
+}
+
+// WithErrorHandler registers a callback invoked when publishing a message fails
+func WithErrorHandler(fn func(msg Message, err error)) Option {
+	return func(o *Outbox) {
+		o.onError = fn
+	}
+}
+
+// WithSentHandler registers a callback invoked after a message is published
+func WithSentHandler(fn func(msg Message, messageID string)) Option {
+	return func(o *Outbox) {
+		o.onSent = fn
+	}
+}
+
+// Outbox persists webhook messages to a Store and publishes them in order in
+// the background. A message that cannot be published blocks the messages
+// behind it until it succeeds, so ordering is preserved across outages and
+// restarts.
+type Outbox struct {
+	sender       Sender
+	store        Store
+	batchSize    int
+	pollInterval time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	onError      func(Message, error)
+	onSent       func(Message, string)
+
+	// mu serializes publishing so Flush and the background loop never send
+	// the same message twice
+	mu      sync.Mutex
+	wake    chan struct{}
+	stateMu sync.Mutex
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// New creates an Outbox that publishes messages from store through sender
+func New(sender Sender, store Store, opts ...Option) *Outbox {
+	o := &Outbox{
+		sender:       sender,
+		store:        store,
+		batchSize:    100,
+		pollInterval: 5 * time.Second,
+		minBackoff:   time.Second,
+		maxBackoff:   time.Minute,
+		wake:         make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// EnqueueOption configures a message passed to Enqueue
+type EnqueueOption func(*Message)
+
+// WithEventType sets the event type the message is published with. See
+// vartiq.WithEventType.
+func WithEventType(eventType string) EnqueueOption {
+	return func(m *Message) {
+		m.EventType = eventType
+	}
+}
+
+// newMessage encodes payload into a message for appID
+func newMessage(appID string, payload interface{}, opts []EnqueueOption) (Message, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return Message{}, fmt.Errorf("outbox: failed to encode payload: %w", err)
+	}
+	msg := Message{AppID: appID, Payload: raw}
+	for _, opt := range opts {
+		opt(&msg)
+	}
+	return msg, nil
+}
+
+// Enqueue persists a message for appID. The payload must be JSON-serializable.
+// Once Enqueue returns without error the message survives a restart.
+func (o *Outbox) Enqueue(ctx context.Context, appID string, payload interface{}, opts ...EnqueueOption) (Message, error) {
+	msg, err := newMessage(appID, payload, opts)
+	if err != nil {
+		return Message{}, err
+	}
+	if msg.EventType != "" {
+		if _, ok := o.sender.(OptionSender); !ok {
+			return Message{}, ErrEventTypeUnsupported
+		}
+	}
+	msg, err = appendMessage(ctx, o.store, msg)
+	if err != nil {
+		return Message{}, err
+	}
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return msg, nil
+}
+
+// Flush publishes every pending message in order and returns the first
+// error encountered. Messages after a failed one are left pending.
+func (o *Outbox) Flush(ctx context.Context) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for {
+		msgs, err := o.store.Pending(ctx, o.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(msgs) == 0 {
+			return nil
+		}
+		for _, msg := range msgs {
+			if err := o.publish(ctx, msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (o *Outbox) publish(ctx context.Context, msg Message) error {
+	var (
+		resp *vartiq.WebhookMessageResponse
+		err  error
+	)
+	if msg.EventType == "" {
+		resp, err = o.sender.Create(ctx, msg.AppID, msg.Payload)
+	} else if sender, ok := o.sender.(OptionSender); ok {
+		resp, err = sender.CreateWithOptions(ctx, msg.AppID, msg.Payload, vartiq.WithEventType(msg.EventType))
+	} else {
+		err = ErrEventTypeUnsupported
+	}
+	if err != nil {
+		if o.onError != nil {
+			o.onError(msg, err)
+		}
+		return err
+	}
+	if err := o.store.MarkSent(ctx, msg.Seq, resp.Data.ID); err != nil {
+		return err
+	}
+	if o.onSent != nil {
+		o.onSent(msg, resp.Data.ID)
+	}
+	return nil
+}
+
+// Start begins publishing pending messages in a background goroutine.
+// Messages left over from a previous run are published first.
+func (o *Outbox) Start(ctx context.Context) {
+	o.stateMu.Lock()
+	defer o.stateMu.Unlock()
+	if o.cancel != nil {
+		return
+	}
+	ctx, o.cancel = context.WithCancel(ctx)
+	o.done = make(chan struct{})
+	go o.run(ctx, o.done)
+}
+
+// Stop halts background publishing and waits for the current attempt to
+// finish. It does not close the underlying store.
+func (o *Outbox) Stop() {
+	o.stateMu.Lock()
+	cancel, done := o.cancel, o.done
+	o.cancel, o.done = nil, nil
+	o.stateMu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+func (o *Outbox) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	backoff := o.minBackoff
+	for {
+		err := o.Flush(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			// Ignore wake-ups while backing off so a burst of enqueues
+			// during an outage does not hammer the API
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			backoff *= 2
+			if backoff > o.maxBackoff {
+				backoff = o.maxBackoff
+			}
+			continue
+		}
+
+		backoff = o.minBackoff
+		timer := time.NewTimer(o.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-o.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/internal/apitest"
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+// fakeSender records published payloads and can be told to fail
+type fakeSender struct {
+	mu    sync.Mutex
+	fail  bool
+	sent  []string
+	count int
+}
+
+func (f *fakeSender) Create(ctx context.Context, appID string, payload interface{}) (*vartiq.WebhookMessageResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail {
+		return nil, errors.New("api unavailable")
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	f.count++
+	f.sent = append(f.sent, appID+":"+string(raw))
+	return &vartiq.WebhookMessageResponse{
+		Data:    vartiq.WebhookMessage{ID: fmt.Sprintf("msg-%d", f.count), AppID: appID},
+		Success: true,
+	}, nil
+}
+
+func (f *fakeSender) setFail(fail bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fail = fail
+}
+
+func (f *fakeSender) published() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.sent...)
+}
+
+func TestOutbox_FlushInOrder(t *testing.T) {
+	sender := &fakeSender{}
+	store := NewMemoryStore()
+	var ids []string
+	ob := New(sender, store, WithBatchSize(2), WithSentHandler(func(msg Message, messageID string) {
+		ids = append(ids, messageID)
+	}))
+	ctx := context.Background()
+
+	for i := 1; i <= 5; i++ {
+		_, err := ob.Enqueue(ctx, "app-1", map[string]int{"n": i})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, ob.Flush(ctx))
+	assert.Equal(t, []string{
+		`app-1:{"n":1}`,
+		`app-1:{"n":2}`,
+		`app-1:{"n":3}`,
+		`app-1:{"n":4}`,
+		`app-1:{"n":5}`,
+	}, sender.published())
+	assert.Equal(t, []string{"msg-1", "msg-2", "msg-3", "msg-4", "msg-5"}, ids)
+
+	pending, err := store.Pending(ctx, 0)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestOutbox_FlushKeepsMessagesOnFailure(t *testing.T) {
+	sender := &fakeSender{fail: true}
+	store := NewMemoryStore()
+	var failures int
+	ob := New(sender, store, WithErrorHandler(func(msg Message, err error) {
+		failures++
+	}))
+	ctx := context.Background()
+
+	_, err := ob.Enqueue(ctx, "app-1", "first")
+	require.NoError(t, err)
+	_, err = ob.Enqueue(ctx, "app-1", "second")
+	require.NoError(t, err)
+
+	assert.Error(t, ob.Flush(ctx))
+	assert.Equal(t, 1, failures)
+	pending, err := store.Pending(ctx, 0)
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+
+	sender.setFail(false)
+	require.NoError(t, ob.Flush(ctx))
+	assert.Equal(t, []string{`app-1:"first"`, `app-1:"second"`}, sender.published())
+}
+
+func TestOutbox_PublishesEventType(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := vartiq.New("key", api.URL)
+	ob := New(client.WebhookMessage, NewMemoryStore())
+	ctx := context.Background()
+
+	_, err := ob.Enqueue(ctx, "app-1", map[string]int{"order": 42}, WithEventType("order.created"))
+	require.NoError(t, err)
+	_, err = ob.Enqueue(ctx, "app-1", "untyped")
+	require.NoError(t, err)
+	require.NoError(t, ob.Flush(ctx))
+
+	messages := api.Messages()
+	require.Len(t, messages, 2)
+	assert.Equal(t, "order.created", messages[0]["eventType"])
+	assert.Nil(t, messages[1]["eventType"])
+}
+
+func TestOutbox_EventTypeNeedsOptionSender(t *testing.T) {
+	ob := New(&fakeSender{}, NewMemoryStore())
+	_, err := ob.Enqueue(context.Background(), "app-1", "typed", WithEventType("order.created"))
+	assert.ErrorIs(t, err, ErrEventTypeUnsupported)
+}
+
+func TestOutbox_Enqueue_InvalidPayload(t *testing.T) {
+	ob := New(&fakeSender{}, NewMemoryStore())
+	_, err := ob.Enqueue(context.Background(), "app-1", make(chan int))
+	assert.Error(t, err)
+}
+
+func TestOutbox_StartPublishesInBackground(t *testing.T) {
+	sender := &fakeSender{fail: true}
+	store := NewMemoryStore()
+	ob := New(sender, store,
+		WithPollInterval(time.Hour),
+		WithBackoff(10*time.Millisecond, 20*time.Millisecond),
+	)
+	ctx := context.Background()
+
+	ob.Start(ctx)
+	defer ob.Stop()
+
+	_, err := ob.Enqueue(ctx, "app-1", "hello")
+	require.NoError(t, err)
+
+	time.Sleep(30 * time.Millisecond)
+	sender.setFail(false)
+
+	assert.Eventually(t, func() bool {
+		return len(sender.published()) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	_, err = ob.Enqueue(ctx, "app-1", "world")
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return len(sender.published()) == 2
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestMemoryStore_MarkSentUnknown(t *testing.T) {
+	store := NewMemoryStore()
+	err := store.MarkSent(context.Background(), 42, "msg")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryStore_Closed(t *testing.T) {
+	store := NewMemoryStore()
+	require.NoError(t, store.Close())
+	_, err := store.Append(context.Background(), "app-1", json.RawMessage(`{}`))
+	assert.ErrorIs(t, err, ErrClosed)
+}
@@ -0,0 +1,319 @@
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect describes the SQL flavour spoken by the database behind a SQLStore
+type Dialect struct {
+	Name string
+	// idColumn is the column definition for the auto-incrementing primary key
+	idColumn string
+	// numbered placeholders ($1) instead of question marks
+	numbered bool
+	// returning reports whether INSERT ... RETURNING id is supported
+	returning bool
+	// skipLocked appends FOR UPDATE SKIP LOCKED when selecting rows to claim
+	skipLocked bool
+	// updateLimit claims with UPDATE ... ORDER BY ... LIMIT instead of a subquery
+	updateLimit bool
+}
+
+var (
+	// DialectSQLite works with SQLite 3.35+ and compatible drivers
+	DialectSQLite = Dialect{
+		Name:      "sqlite",
+		idColumn:  "INTEGER PRIMARY KEY AUTOINCREMENT",
+		returning: true,
+	}
+	// DialectPostgres works with PostgreSQL 9.5+
+	DialectPostgres = Dialect{
+		Name:       "postgres",
+		idColumn:   "BIGSERIAL PRIMARY KEY",
+		numbered:   true,
+		returning:  true,
+		skipLocked: true,
+	}
+	// DialectMySQL works with MySQL 8.0+ and MariaDB
+	DialectMySQL = Dialect{
+		Name:        "mysql",
+		idColumn:    "BIGINT AUTO_INCREMENT PRIMARY KEY",
+		updateLimit: true,
+	}
+)
+
+// rebind rewrites ? placeholders for dialects that number their parameters
+func (d Dialect) rebind(query string) string {
+	if !d.numbered {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Querier is implemented by *sql.Tx, *sql.DB and *sql.Conn
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// SQLOption configures a SQLStore
+type SQLOption func(*SQLStore)
+
+// WithTable sets the outbox table name. It defaults to vartiq_outbox.
+func WithTable(name string) SQLOption {
+	return func(s *SQLStore) {
+		if name != "" {
+			s.table = name
+		}
+	}
+}
+
+// WithLease sets how long claimed rows are reserved for this relay before
+// another relay may take them over. It defaults to 30 seconds.
+func WithLease(d time.Duration) SQLOption {
+	return func(s *SQLStore) {
+		if d > 0 {
+			s.lease = d
+		}
+	}
+}
+
+// WithOwner sets the identifier recorded on rows claimed by this store.
+// It defaults to a random value.
+func WithOwner(owner string) SQLOption {
+	return func(s *SQLStore) {
+		if owner != "" {
+			s.owner = owner
+		}
+	}
+}
+
+// SQLStore is a transactional outbox Store backed by database/sql. Messages
+// are written with Enqueue inside the caller's transaction, so they are only
+// published if the business write commits. Several relays may share a table:
+// rows are claimed for a lease before being published, and rows locked or
+// leased by another relay are skipped.
+type SQLStore struct {
+	db      *sql.DB
+	dialect Dialect
+	table   string
+	lease   time.Duration
+	owner   string
+}
+
+// NewSQLStore creates a SQLStore using db. Call CreateSchema once to create
+// the outbox table.
+func NewSQLStore(db *sql.DB, dialect Dialect, opts ...SQLOption) *SQLStore {
+	s := &SQLStore{
+		db:      db,
+		dialect: dialect,
+		table:   "vartiq_outbox",
+		lease:   30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.owner == "" {
+		b := make([]byte, 8)
+		_, _ = rand.Read(b)
+		s.owner = hex.EncodeToString(b)
+	}
+	return s
+}
+
+// Schema returns the CREATE TABLE statement for the outbox table
+func (s *SQLStore) Schema() string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id %s,
+	app_id VARCHAR(255) NOT NULL,
+	event_type VARCHAR(255),
+	payload TEXT NOT NULL,
+	created_at BIGINT NOT NULL,
+	claimed_by VARCHAR(64),
+	claimed_until BIGINT,
+	sent_at BIGINT,
+	message_id VARCHAR(255)
+)`, s.table, s.dialect.idColumn)
+}
+
+// CreateSchema creates the outbox table if it does not exist, and adds the
+// columns missing from tables created by earlier versions of this package.
+// It is safe to call on every start.
+func (s *SQLStore) CreateSchema(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, s.Schema()); err != nil {
+		return fmt.Errorf("outbox: failed to create schema: %w", err)
+	}
+	return s.addColumn(ctx, "event_type", "VARCHAR(255)")
+}
+
+// addColumn adds a nullable column to the outbox table unless it exists
+func (s *SQLStore) addColumn(ctx context.Context, name, definition string) error {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s WHERE 1 = 0", name, s.table))
+	if err == nil {
+		return rows.Close()
+	}
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", s.table, name, definition)); err != nil {
+		return fmt.Errorf("outbox: failed to add column %s: %w", name, err)
+	}
+	return nil
+}
+
+// Enqueue writes a message for appID using tx, which is usually the
+// transaction that performs the related business write. The payload must be
+// JSON-serializable.
+func (s *SQLStore) Enqueue(ctx context.Context, tx Querier, appID string, payload interface{}, opts ...EnqueueOption) (Message, error) {
+	msg, err := newMessage(appID, payload, opts)
+	if err != nil {
+		return Message{}, err
+	}
+	return s.insert(ctx, tx, msg)
+}
+
+func (s *SQLStore) insert(ctx context.Context, q Querier, msg Message) (Message, error) {
+	msg = Message{
+		AppID:     msg.AppID,
+		EventType: msg.EventType,
+		Payload:   append(json.RawMessage(nil), msg.Payload...),
+		CreatedAt: time.Now().UTC(),
+	}
+	eventType := sql.NullString{String: msg.EventType, Valid: msg.EventType != ""}
+	query := s.dialect.rebind(fmt.Sprintf(
+		"INSERT INTO %s (app_id, event_type, payload, created_at) VALUES (?, ?, ?, ?)", s.table))
+	args := []interface{}{msg.AppID, eventType, string(msg.Payload), msg.CreatedAt.UnixMilli()}
+
+	var id int64
+	if s.dialect.returning {
+		if err := q.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id); err != nil {
+			return Message{}, fmt.Errorf("outbox: failed to enqueue message: %w", err)
+		}
+	} else {
+		res, err := q.ExecContext(ctx, query, args...)
+		if err != nil {
+			return Message{}, fmt.Errorf("outbox: failed to enqueue message: %w", err)
+		}
+		if id, err = res.LastInsertId(); err != nil {
+			return Message{}, fmt.Errorf("outbox: failed to enqueue message: %w", err)
+		}
+	}
+	msg.Seq = uint64(id)
+	return msg, nil
+}
+
+// Append writes a message outside of any caller transaction
+func (s *SQLStore) Append(ctx context.Context, appID string, payload json.RawMessage) (Message, error) {
+	return s.insert(ctx, s.db, Message{AppID: appID, Payload: payload})
+}
+
+// AppendMessage writes msg, including its event type, outside of any caller
+// transaction
+func (s *SQLStore) AppendMessage(ctx context.Context, msg Message) (Message, error) {
+	return s.insert(ctx, s.db, msg)
+}
+
+// Pending claims up to limit unsent messages for this store's owner and
+// returns them oldest first. Rows already claimed by this owner are returned
+// again so a failed publish is retried by the same relay.
+func (s *SQLStore) Pending(ctx context.Context, limit int) ([]Message, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	now := time.Now()
+	until := now.Add(s.lease).UnixMilli()
+	claimable := "sent_at IS NULL AND (claimed_until IS NULL OR claimed_until < ? OR claimed_by = ?)"
+
+	var query string
+	if s.dialect.updateLimit {
+		query = fmt.Sprintf(
+			"UPDATE %s SET claimed_by = ?, claimed_until = ? WHERE %s ORDER BY id LIMIT %d",
+			s.table, claimable, limit)
+	} else {
+		lock := ""
+		if s.dialect.skipLocked {
+			lock = " FOR UPDATE SKIP LOCKED"
+		}
+		query = fmt.Sprintf(
+			"UPDATE %s SET claimed_by = ?, claimed_until = ? WHERE id IN (SELECT id FROM %s WHERE %s ORDER BY id LIMIT %d%s)",
+			s.table, s.table, claimable, limit, lock)
+	}
+	args := []interface{}{s.owner, until, now.UnixMilli(), s.owner}
+	if _, err := s.db.ExecContext(ctx, s.dialect.rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("outbox: failed to claim messages: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.dialect.rebind(fmt.Sprintf(
+		"SELECT id, app_id, event_type, payload, created_at FROM %s WHERE sent_at IS NULL AND claimed_by = ? ORDER BY id LIMIT %d",
+		s.table, limit)), s.owner)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: failed to read claimed messages: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Message
+	for rows.Next() {
+		var (
+			id        int64
+			appID     string
+			eventType sql.NullString
+			payload   string
+			createdAt int64
+		)
+		if err := rows.Scan(&id, &appID, &eventType, &payload, &createdAt); err != nil {
+			return nil, fmt.Errorf("outbox: failed to read claimed messages: %w", err)
+		}
+		out = append(out, Message{
+			Seq:       uint64(id),
+			AppID:     appID,
+			EventType: eventType.String,
+			Payload:   json.RawMessage(payload),
+			CreatedAt: time.UnixMilli(createdAt).UTC(),
+		})
+	}
+	return out, rows.Err()
+}
+
+func (s *SQLStore) MarkSent(ctx context.Context, seq uint64, messageID string) error {
+	res, err := s.db.ExecContext(ctx, s.dialect.rebind(fmt.Sprintf(
+		"UPDATE %s SET sent_at = ?, message_id = ?, claimed_by = NULL, claimed_until = NULL WHERE id = ? AND sent_at IS NULL",
+		s.table)), time.Now().UnixMilli(), messageID, int64(seq))
+	if err != nil {
+		return fmt.Errorf("outbox: failed to mark message sent: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("outbox: failed to mark message sent: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Close does nothing; the caller owns the *sql.DB
+func (s *SQLStore) Close() error {
+	return nil
+}
+
+// NewRelay creates an Outbox that claims messages from store and publishes
+// them through sender. Run one relay per process; relays sharing a table
+// split the pending rows between them.
+func NewRelay(sender Sender, store *SQLStore, opts ...Option) *Outbox {
+	return New(sender, store, opts...)
+}
@@ -0,0 +1,171 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func newTestSQLStore(t *testing.T, opts ...SQLOption) (*SQLStore, *sql.DB) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "outbox.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+
+	store := NewSQLStore(db, DialectSQLite, opts...)
+	require.NoError(t, store.CreateSchema(context.Background()))
+	return store, db
+}
+
+func TestDialect_Rebind(t *testing.T) {
+	query := "UPDATE t SET a = ? WHERE b = ?"
+	assert.Equal(t, query, DialectSQLite.rebind(query))
+	assert.Equal(t, query, DialectMySQL.rebind(query))
+	assert.Equal(t, "UPDATE t SET a = $1 WHERE b = $2", DialectPostgres.rebind(query))
+}
+
+func TestSQLStore_EnqueueInTransaction(t *testing.T) {
+	store, db := newTestSQLStore(t)
+	ctx := context.Background()
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	_, err = store.Enqueue(ctx, tx, "app-1", map[string]string{"order": "rolled-back"})
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	tx, err = db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	msg, err := store.Enqueue(ctx, tx, "app-1", map[string]string{"order": "committed"}, WithEventType("order.created"))
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	pending, err := store.Pending(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, msg.Seq, pending[0].Seq)
+	assert.Equal(t, "order.created", pending[0].EventType)
+	assert.JSONEq(t, `{"order":"committed"}`, string(pending[0].Payload))
+}
+
+func TestSQLStore_CreateSchemaMigrates(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "outbox.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	// the table as created before event types were added
+	_, err = db.ExecContext(ctx, `CREATE TABLE vartiq_outbox (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	app_id VARCHAR(255) NOT NULL,
+	payload TEXT NOT NULL,
+	created_at BIGINT NOT NULL,
+	claimed_by VARCHAR(64),
+	claimed_until BIGINT,
+	sent_at BIGINT,
+	message_id VARCHAR(255)
+)`)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `INSERT INTO vartiq_outbox (app_id, payload, created_at) VALUES ('app-1', '"old"', 1)`)
+	require.NoError(t, err)
+
+	store := NewSQLStore(db, DialectSQLite)
+	require.NoError(t, store.CreateSchema(ctx))
+	require.NoError(t, store.CreateSchema(ctx))
+	_, err = store.AppendMessage(ctx, Message{AppID: "app-1", EventType: "order.created", Payload: []byte(`"new"`)})
+	require.NoError(t, err)
+
+	pending, err := store.Pending(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Empty(t, pending[0].EventType)
+	assert.Equal(t, "order.created", pending[1].EventType)
+}
+
+func TestSQLStore_ClaimsAreExclusive(t *testing.T) {
+	first, db := newTestSQLStore(t, WithOwner("relay-1"))
+	second := NewSQLStore(db, DialectSQLite, WithOwner("relay-2"))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_, err := first.Append(ctx, "app-1", []byte(`{}`))
+		require.NoError(t, err)
+	}
+
+	claimed, err := first.Pending(ctx, 2)
+	require.NoError(t, err)
+	assert.Len(t, claimed, 2)
+
+	others, err := second.Pending(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, others, 1)
+	assert.Equal(t, uint64(3), others[0].Seq)
+
+	// The same owner sees its own claims again until they are sent
+	again, err := first.Pending(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, again, 2)
+}
+
+func TestSQLStore_ExpiredLeaseIsReclaimed(t *testing.T) {
+	first, db := newTestSQLStore(t, WithOwner("relay-1"), WithLease(time.Millisecond))
+	second := NewSQLStore(db, DialectSQLite, WithOwner("relay-2"))
+	ctx := context.Background()
+
+	_, err := first.Append(ctx, "app-1", []byte(`{}`))
+	require.NoError(t, err)
+	claimed, err := first.Pending(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	time.Sleep(5 * time.Millisecond)
+	reclaimed, err := second.Pending(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, reclaimed, 1)
+}
+
+func TestSQLStore_MarkSent(t *testing.T) {
+	store, db := newTestSQLStore(t)
+	ctx := context.Background()
+
+	msg, err := store.Append(ctx, "app-1", []byte(`{}`))
+	require.NoError(t, err)
+	require.NoError(t, store.MarkSent(ctx, msg.Seq, "msg-1"))
+	assert.ErrorIs(t, store.MarkSent(ctx, msg.Seq, "msg-1"), ErrNotFound)
+
+	var messageID string
+	require.NoError(t, db.QueryRow("SELECT message_id FROM vartiq_outbox WHERE id = ?", msg.Seq).Scan(&messageID))
+	assert.Equal(t, "msg-1", messageID)
+
+	pending, err := store.Pending(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestRelay_PublishesCommittedMessages(t *testing.T) {
+	store, db := newTestSQLStore(t)
+	sender := &fakeSender{}
+	relay := NewRelay(sender, store)
+	ctx := context.Background()
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	for _, event := range []string{"created", "paid", "shipped"} {
+		_, err := store.Enqueue(ctx, tx, "app-1", map[string]string{"event": event})
+		require.NoError(t, err)
+	}
+	require.NoError(t, tx.Commit())
+
+	require.NoError(t, relay.Flush(ctx))
+	assert.Equal(t, []string{
+		`app-1:{"event":"created"}`,
+		`app-1:{"event":"paid"}`,
+		`app-1:{"event":"shipped"}`,
+	}, sender.published())
+}
@@ -0,0 +1,62 @@
+// Package outbox provides a durable local outbox for webhook messages.
+// Messages are persisted to a Store before they are published, so an event
+// is not lost when the Vartiq API is unreachable.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by a Store when a message sequence number is unknown
+var ErrNotFound = errors.New("outbox: message not found")
+
+// ErrClosed is returned when using a Store or Outbox after Close
+var ErrClosed = errors.New("outbox: closed")
+
+// Message is a webhook message waiting in the outbox
+type Message struct {
+	Seq   uint64 `json:"seq"`
+	AppID string `json:"appId"`
+	// EventType is optional; see WithEventType
+	EventType string          `json:"eventType,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// Store persists pending outbox messages. Implementations must return
+// pending messages in the order they were appended.
+type Store interface {
+	// Append persists a new message and assigns its sequence number
+	Append(ctx context.Context, appID string, payload json.RawMessage) (Message, error)
+	// Pending returns up to limit unsent messages, oldest first
+	Pending(ctx context.Context, limit int) ([]Message, error)
+	// MarkSent records that the message was accepted by the API as messageID
+	MarkSent(ctx context.Context, seq uint64, messageID string) error
+	// Close releases any resources held by the store
+	Close() error
+}
+
+// MessageStore is a Store that also persists the event type of a message.
+// The stores in this package implement it; messages with an event type can
+// only be appended to one.
+type MessageStore interface {
+	Store
+	// AppendMessage persists the AppID, EventType and Payload of msg as a new
+	// message and returns it with its sequence number and creation time
+	AppendMessage(ctx context.Context, msg Message) (Message, error)
+}
+
+// appendMessage appends msg to store, using AppendMessage when the store
+// supports it
+func appendMessage(ctx context.Context, store Store, msg Message) (Message, error) {
+	if s, ok := store.(MessageStore); ok {
+		return s.AppendMessage(ctx, msg)
+	}
+	if msg.EventType != "" {
+		return Message{}, ErrEventTypeUnsupported
+	}
+	return store.Append(ctx, msg.AppID, msg.Payload)
+}
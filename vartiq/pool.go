@@ -0,0 +1,173 @@
+package vartiq
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultPoolIdleTimeout is how long a ClientPool keeps an unused client
+const DefaultPoolIdleTimeout = 10 * time.Minute
+
+// minEvictInterval bounds how often the pool looks for idle clients
+const minEvictInterval = 10 * time.Millisecond
+
+// PoolOption configures a ClientPool
+type PoolOption func(*ClientPool)
+
+// WithPoolIdleTimeout sets how long a client may go unused before the pool
+// evicts it. Zero disables eviction.
+func WithPoolIdleTimeout(d time.Duration) PoolOption {
+	return func(p *ClientPool) {
+		p.idleTimeout = d
+	}
+}
+
+// WithPoolRateLimit limits the requests of all clients in the pool together
+// to rps requests per second with bursts of up to burst requests. rps <= 0
+// means no limit.
+func WithPoolRateLimit(rps float64, burst int) PoolOption {
+	return func(p *ClientPool) {
+		p.limiter = NewRateLimiter(rps, burst)
+	}
+}
+
+// WithPoolTransport sets the HTTP transport shared by the pool's clients.
+// It defaults to a clone of http.DefaultTransport.
+func WithPoolTransport(rt http.RoundTripper) PoolOption {
+	return func(p *ClientPool) {
+		p.transport = rt
+	}
+}
+
+// WithPoolClientOptions sets options applied to every client the pool creates
+func WithPoolClientOptions(opts ...Option) PoolOption {
+	return func(p *ClientPool) {
+		p.opts = append(p.opts, opts...)
+	}
+}
+
+type pooledClient struct {
+	client   *Client
+	lastUsed time.Time
+}
+
+// ClientPool creates and caches one Client per API key, for services that
+// call Vartiq on behalf of many tenants. All clients share one HTTP
+// transport and, if configured, one rate limit budget. Clients that go
+// unused for the idle timeout are evicted. A ClientPool is safe for
+// concurrent use.
+type ClientPool struct {
+	opts        []Option
+	idleTimeout time.Duration
+	transport   http.RoundTripper
+	limiter     *RateLimiter
+
+	mu      sync.Mutex
+	clients map[string]*pooledClient
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewClientPool creates an empty pool. Call Close to stop evicting idle
+// clients and release the shared connections.
+func NewClientPool(opts ...PoolOption) *ClientPool {
+	p := &ClientPool{
+		idleTimeout: DefaultPoolIdleTimeout,
+		clients:     make(map[string]*pooledClient),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.transport == nil {
+		p.transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	if p.idleTimeout > 0 {
+		p.stop = make(chan struct{})
+		p.done = make(chan struct{})
+		go p.evictLoop(p.stop, p.done)
+	}
+	return p
+}
+
+// Get returns the client for apiKey, creating it on first use
+func (p *ClientPool) Get(apiKey string) *Client {
+	now := time.Now()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if pc, ok := p.clients[apiKey]; ok {
+		pc.lastUsed = now
+		return pc.client
+	}
+
+	opts := append([]Option(nil), p.opts...)
+	opts = append(opts, WithHTTPTransport(p.transport))
+	if p.limiter != nil {
+		opts = append(opts, WithRateLimiter(p.limiter))
+	}
+	c := NewWithOptions(apiKey, opts...)
+	p.clients[apiKey] = &pooledClient{client: c, lastUsed: now}
+	return c
+}
+
+// Len returns the number of cached clients
+func (p *ClientPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.clients)
+}
+
+// Evict removes the client for apiKey, for example after the tenant's key
+// was revoked. Clients already handed out keep working.
+func (p *ClientPool) Evict(apiKey string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.clients, apiKey)
+}
+
+// Close stops idle eviction, drops all cached clients and closes the idle
+// connections of the shared transport
+func (p *ClientPool) Close() {
+	p.mu.Lock()
+	stop, done := p.stop, p.done
+	p.stop = nil
+	p.clients = make(map[string]*pooledClient)
+	p.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	if t, ok := p.transport.(interface{ CloseIdleConnections() }); ok {
+		t.CloseIdleConnections()
+	}
+}
+
+func (p *ClientPool) evictLoop(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(max(p.idleTimeout/2, minEvictInterval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			p.evictIdle(now)
+		}
+	}
+}
+
+// evictIdle removes the clients not used since idleTimeout before now and
+// returns how many were removed
+func (p *ClientPool) evictIdle(now time.Time) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for key, pc := range p.clients {
+		if now.Sub(pc.lastUsed) >= p.idleTimeout {
+			delete(p.clients, key)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,112 @@
+package vartiq
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientPool_Get(t *testing.T) {
+	var mu sync.Mutex
+	keys := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys[r.Header.Get("x-api-key")]++
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[],"success":true}`))
+	}))
+	defer server.Close()
+
+	pool := NewClientPool(WithPoolClientOptions(WithBaseURL(server.URL)))
+	defer pool.Close()
+
+	a := pool.Get("tenant-a")
+	assert.Same(t, a, pool.Get("tenant-a"))
+	b := pool.Get("tenant-b")
+	assert.NotSame(t, a, b)
+	assert.Equal(t, 2, pool.Len())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := "tenant-a"
+			if i%2 == 1 {
+				key = "tenant-b"
+			}
+			_, err := pool.Get(key).Project.List(context.Background())
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, map[string]int{"tenant-a": 5, "tenant-b": 5}, keys)
+	assert.Equal(t, 2, pool.Len())
+}
+
+func TestClientPool_SharesRateLimiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[],"success":true}`))
+	}))
+	defer server.Close()
+
+	pool := NewClientPool(
+		WithPoolRateLimit(1, 1),
+		WithPoolClientOptions(WithBaseURL(server.URL)),
+	)
+	defer pool.Close()
+
+	a, b := pool.Get("tenant-a"), pool.Get("tenant-b")
+	assert.Same(t, a.rateLimiter, b.rateLimiter)
+
+	_, err := a.Project.List(context.Background())
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = b.Project.List(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClientPool_EvictIdle(t *testing.T) {
+	pool := NewClientPool(WithPoolIdleTimeout(time.Minute))
+	defer pool.Close()
+
+	a := pool.Get("tenant-a")
+	pool.Get("tenant-b")
+	pool.mu.Lock()
+	pool.clients["tenant-b"].lastUsed = time.Now().Add(-2 * time.Minute)
+	pool.mu.Unlock()
+
+	assert.Equal(t, 1, pool.evictIdle(time.Now()))
+	assert.Equal(t, 1, pool.Len())
+	assert.Same(t, a, pool.Get("tenant-a"))
+
+	pool.Evict("tenant-a")
+	assert.Equal(t, 0, pool.Len())
+	assert.NotSame(t, a, pool.Get("tenant-a"))
+}
+
+func TestClientPool_EvictsInBackground(t *testing.T) {
+	pool := NewClientPool(WithPoolIdleTimeout(20 * time.Millisecond))
+	defer pool.Close()
+
+	pool.Get("tenant-a")
+	assert.Eventually(t, func() bool { return pool.Len() == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestClientPool_TinyIdleTimeout(t *testing.T) {
+	pool := NewClientPool(WithPoolIdleTimeout(time.Nanosecond))
+	defer pool.Close()
+
+	pool.Get("tenant-a")
+	assert.Eventually(t, func() bool { return pool.Len() == 0 }, time.Second, 5*time.Millisecond)
+}
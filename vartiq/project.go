@@ -2,6 +2,8 @@ package vartiq
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 )
 
 type ProjectService struct {
@@ -9,17 +11,25 @@ type ProjectService struct {
 }
 
 type Project struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Company     string `json:"company"`
-	CreatedAt   string `json:"createdAt"`
-	UpdatedAt   string `json:"updatedAt"`
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Company     string            `json:"company"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	CreatedAt   Time              `json:"createdAt"`
+	UpdatedAt   Time              `json:"updatedAt"`
 }
 
+// Created returns when the project was created
+func (p Project) Created() time.Time { return p.CreatedAt.Time }
+
+// Updated returns when the project was last updated
+func (p Project) Updated() time.Time { return p.UpdatedAt.Time }
+
 type CreateProjectRequest struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
 }
 
 type CreateProjectResponse struct {
@@ -30,8 +40,7 @@ type CreateProjectResponse struct {
 
 func (s *ProjectService) Create(ctx context.Context, req *CreateProjectRequest) (*CreateProjectResponse, error) {
 	resp := &CreateProjectResponse{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+	_, err := s.client.request(ctx, "project.create").
 		SetBody(req).
 		SetResult(resp).
 		Post("/projects")
@@ -41,8 +50,8 @@ func (s *ProjectService) Create(ctx context.Context, req *CreateProjectRequest)
 	return resp, nil
 }
 
-// List all projects
-func (s *ProjectService) List(ctx context.Context) (*struct {
+// List all projects, or those matching the label selector given in opts
+func (s *ProjectService) List(ctx context.Context, opts ...ListOption) (*struct {
 	Data    []Project `json:"data"`
 	Message string    `json:"message"`
 	Success bool      `json:"success"`
@@ -52,13 +61,18 @@ func (s *ProjectService) List(ctx context.Context) (*struct {
 		Message string    `json:"message"`
 		Success bool      `json:"success"`
 	}{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+	o, err := newListOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.client.request(ctx, "project.list").
+		SetQueryParams(o.queryParams()).
 		SetResult(resp).
 		Get("/projects")
 	if err != nil {
 		return nil, err
 	}
+	resp.Data = filterLabels(resp.Data, o.selector, func(p Project) map[string]string { return p.Metadata })
 	return resp, nil
 }
 
@@ -73,8 +87,7 @@ func (s *ProjectService) Get(ctx context.Context, projectID string) (*struct {
 		Message string  `json:"message"`
 		Success bool    `json:"success"`
 	}{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+	_, err := s.client.request(ctx, "project.get").
 		SetResult(resp).
 		Get("/projects/" + projectID)
 	if err != nil {
@@ -87,6 +100,21 @@ func (s *ProjectService) Get(ctx context.Context, projectID string) (*struct {
 type UpdateProjectRequest struct {
 	Name        string `json:"name,omitempty"`
 	Description string `json:"description,omitempty"`
+	// Metadata replaces the labels of the project. A nil map leaves them
+	// unchanged and a non-nil empty map clears them.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// MarshalJSON sends a non-nil empty Metadata, which omitempty would drop
+func (r UpdateProjectRequest) MarshalJSON() ([]byte, error) {
+	type fields UpdateProjectRequest
+	if r.Metadata == nil {
+		return json.Marshal(fields(r))
+	}
+	return json.Marshal(struct {
+		fields
+		Metadata map[string]string `json:"metadata"`
+	}{fields(r), r.Metadata})
 }
 
 // Update a project by ID
@@ -100,8 +128,7 @@ func (s *ProjectService) Update(ctx context.Context, projectID string, req *Upda
 		Message string  `json:"message"`
 		Success bool    `json:"success"`
 	}{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+	_, err := s.client.request(ctx, "project.update").
 		SetBody(req).
 		SetResult(resp).
 		Put("/projects/" + projectID)
@@ -113,8 +140,7 @@ func (s *ProjectService) Update(ctx context.Context, projectID string, req *Upda
 
 // Delete a project by ID
 func (s *ProjectService) Delete(ctx context.Context, projectID string) error {
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+	_, err := s.client.request(ctx, "project.delete").
 		Delete("/projects/" + projectID)
 	return err
 }
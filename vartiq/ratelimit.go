@@ -0,0 +1,248 @@
+package vartiq
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRateLimitRetries is how many times a request rejected with 429 is
+// retried after waiting for the server's rate limit to reset
+const maxRateLimitRetries = 3
+
+// RateLimiter is a token bucket limiter. Requests wait for a token instead of
+// failing, and the bucket pauses when the server reports that its limit has
+// been reached.
+type RateLimiter struct {
+	mu          sync.Mutex
+	rate        float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+// NewRateLimiter creates a limiter that allows rps requests per second with
+// bursts of up to burst requests. With rps <= 0 requests are not limited,
+// but the limiter still pauses when the server reports its limit.
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a request may be sent or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		delay := l.reserve(time.Now())
+		if delay <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token if one is available, otherwise it returns how long
+// to wait before trying again
+func (l *RateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+	if l.rate <= 0 {
+		return 0
+	}
+	if now.After(l.last) {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+		l.last = now
+	}
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// pause stops handing out tokens until t
+func (l *RateLimiter) pause(t time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if t.After(l.pausedUntil) {
+		l.pausedUntil = t
+	}
+}
+
+// observe adapts the limiter to the rate limit headers of a response
+func (l *RateLimiter) observe(resp *http.Response, now time.Time) {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if t, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now); ok {
+			l.pause(t)
+			return
+		}
+	}
+
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		if resp.StatusCode == http.StatusTooManyRequests {
+			l.pause(now.Add(time.Second))
+		}
+		return
+	}
+	l.mu.Lock()
+	if float64(remaining) < l.tokens {
+		l.tokens = float64(remaining)
+	}
+	l.mu.Unlock()
+	if remaining <= 0 {
+		if t, ok := parseRateLimitReset(resp.Header.Get("X-RateLimit-Reset"), now); ok {
+			l.pause(t)
+		} else {
+			l.pause(now.Add(time.Second))
+		}
+	}
+}
+
+// parseRetryAfter parses a Retry-After value given in seconds or as an HTTP date
+func parseRetryAfter(v string, now time.Time) (time.Time, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return time.Time{}, false
+	}
+	if secs, err := strconv.ParseFloat(v, 64); err == nil {
+		return now.Add(time.Duration(secs * float64(time.Second))), true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// parseRateLimitReset parses X-RateLimit-Reset, which servers send either as
+// a Unix timestamp or as seconds until the window resets
+func parseRateLimitReset(v string, now time.Time) (time.Time, bool) {
+	n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if n > 1e9 {
+		return time.Unix(n, 0), true
+	}
+	return now.Add(time.Duration(n) * time.Second), true
+}
+
+// WithRateLimit limits all requests made by the client to rps requests per
+// second with bursts of up to burst requests
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		c.rateLimiter = NewRateLimiter(rps, burst)
+	}
+}
+
+// WithRateLimiter limits all requests made by the client with l. Clients
+// given the same limiter share its budget.
+func WithRateLimiter(l *RateLimiter) Option {
+	return func(c *Client) {
+		c.rateLimiter = l
+	}
+}
+
+// WithServiceRateLimit limits requests made by one service. The service is
+// one of "project", "app", "webhook", "webhook_message" or "event_type".
+// Service limits apply in addition to the global limit.
+func WithServiceRateLimit(service string, rps float64, burst int) Option {
+	return func(c *Client) {
+		if c.serviceLimiters == nil {
+			c.serviceLimiters = make(map[string]*RateLimiter)
+		}
+		c.serviceLimiters[service] = NewRateLimiter(rps, burst)
+	}
+}
+
+// limiters returns the limiters that apply to an operation
+func (c *Client) limiters(operation string) []*RateLimiter {
+	var out []*RateLimiter
+	if c.rateLimiter != nil {
+		out = append(out, c.rateLimiter)
+	}
+	service, _, _ := strings.Cut(operation, ".")
+	if l, ok := c.serviceLimiters[service]; ok {
+		out = append(out, l)
+	}
+	return out
+}
+
+// rateLimitTransport waits for the client's rate limiters before each request
+// and retries requests rejected with 429 once the limit resets
+type rateLimitTransport struct {
+	client *Client
+	base   http.RoundTripper
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	limiters := t.client.limiters(Operation(ctx))
+	for attempt := 0; ; attempt++ {
+		for _, l := range limiters {
+			if err := l.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		for _, l := range limiters {
+			l.observe(resp, now)
+		}
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= maxRateLimitRetries {
+			return resp, nil
+		}
+
+		retry, ok := rewind(req)
+		if !ok {
+			return resp, nil
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		t.client.countRetry(ctx)
+		req = retry
+	}
+}
+
+// rewind returns a copy of req that can be sent again
+func rewind(req *http.Request) (*http.Request, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req.Clone(req.Context()), true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	retry := req.Clone(req.Context())
+	retry.Body = body
+	return retry, true
+}
@@ -0,0 +1,152 @@
+package vartiq
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Wait(t *testing.T) {
+	l := NewRateLimiter(100, 2)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		require.NoError(t, l.Wait(ctx))
+	}
+	// Two requests use the burst, the next two wait about 10ms each
+	assert.GreaterOrEqual(t, time.Since(start), 15*time.Millisecond)
+}
+
+func TestRateLimiter_WaitHonoursContext(t *testing.T) {
+	l := NewRateLimiter(0.1, 1)
+	require.NoError(t, l.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l.Wait(ctx), context.DeadlineExceeded)
+}
+
+func TestRateLimiter_Unlimited(t *testing.T) {
+	for _, rps := range []float64{0, -1} {
+		l := NewRateLimiter(rps, 1)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		for i := 0; i < 100; i++ {
+			require.NoError(t, l.Wait(ctx), rps)
+		}
+		cancel()
+
+		// A server pause still applies
+		now := time.Now()
+		l.pause(now.Add(time.Minute))
+		assert.Greater(t, l.reserve(now), 59*time.Second)
+	}
+}
+
+func TestRateLimiter_Observe(t *testing.T) {
+	now := time.Now()
+
+	l := NewRateLimiter(100, 10)
+	l.observe(&http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"X-Ratelimit-Remaining": []string{"0"},
+			"X-Ratelimit-Reset":     []string{"2"},
+		},
+	}, now)
+	assert.Equal(t, 2*time.Second, l.reserve(now))
+
+	l = NewRateLimiter(100, 10)
+	l.observe(&http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"3"}},
+	}, now)
+	assert.Equal(t, 3*time.Second, l.reserve(now))
+
+	l = NewRateLimiter(100, 10)
+	l.observe(&http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"X-Ratelimit-Remaining": []string{"1"}},
+	}, now)
+	assert.Equal(t, time.Duration(0), l.reserve(now))
+	assert.Greater(t, l.reserve(now), time.Duration(0))
+}
+
+func TestParseRateLimitReset(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	reset, ok := parseRateLimitReset("1700000030", now)
+	require.True(t, ok)
+	assert.Equal(t, now.Add(30*time.Second), reset)
+
+	reset, ok = parseRateLimitReset("30", now)
+	require.True(t, ok)
+	assert.Equal(t, now.Add(30*time.Second), reset)
+
+	_, ok = parseRateLimitReset("soon", now)
+	assert.False(t, ok)
+}
+
+func TestClient_RetriesAfterTooManyRequests(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0.01")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"id":"project-1","name":"Test"},"success":true}`))
+	}))
+	defer server.Close()
+
+	var retries int
+	client := NewWithOptions("test-key",
+		WithBaseURL(server.URL),
+		WithRateLimit(100, 1),
+		WithMiddleware(func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				resp, err := next(req)
+				retries = RetryCount(req.Context())
+				return resp, err
+			}
+		}),
+	)
+	resp, err := client.Project.Create(context.Background(), &CreateProjectRequest{Name: "Test"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, retries)
+	assert.Equal(t, "project-1", resp.Data.ID)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestClient_ServiceRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[],"success":true}`))
+	}))
+	defer server.Close()
+
+	client := NewWithOptions("test-key", WithBaseURL(server.URL), WithServiceRateLimit("app", 0.1, 1))
+	assert.Len(t, client.limiters("app.list"), 1)
+	assert.Empty(t, client.limiters("project.list"))
+
+	ctx := context.Background()
+	_, err := client.App.List(ctx, "project-1")
+	require.NoError(t, err)
+
+	// Projects are not limited
+	_, err = client.Project.List(ctx)
+	require.NoError(t, err)
+
+	// The app bucket is empty, so the next call waits until the context expires
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = client.App.List(ctx, "project-1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
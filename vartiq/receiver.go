@@ -0,0 +1,201 @@
+package vartiq
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"unicode/utf8"
+)
+
+// SignatureHeader is the request header carrying the signature of a delivery
+const SignatureHeader = "X-Vartiq-Signature"
+
+// DefaultMaxBodySize is the largest delivery body ReceiverMiddleware reads
+const DefaultMaxBodySize = 1 << 20
+
+// ErrBodyTooLarge is returned when a delivery body exceeds the maximum size
+var ErrBodyTooLarge = errors.New("vartiq: webhook body too large")
+
+// Delivery is a webhook request received from Vartiq
+type Delivery struct {
+	ReceivedAt time.Time
+	Method     string
+	Path       string
+	Header     http.Header
+	Body       []byte
+}
+
+// Signature returns the value of the signature header
+func (d *Delivery) Signature() string {
+	return d.Header.Get(SignatureHeader)
+}
+
+// deliveryJSON is the JSON form of a Delivery. Bodies that are valid UTF-8
+// are stored as text so archives stay readable; other bodies are base64
+// encoded.
+type deliveryJSON struct {
+	ReceivedAt time.Time   `json:"receivedAt"`
+	Method     string      `json:"method,omitempty"`
+	Path       string      `json:"path,omitempty"`
+	Header     http.Header `json:"header"`
+	Body       *string     `json:"body,omitempty"`
+	BodyBase64 []byte      `json:"bodyBase64,omitempty"`
+}
+
+func (d Delivery) MarshalJSON() ([]byte, error) {
+	out := deliveryJSON{
+		ReceivedAt: d.ReceivedAt,
+		Method:     d.Method,
+		Path:       d.Path,
+		Header:     d.Header,
+	}
+	if utf8.Valid(d.Body) {
+		body := string(d.Body)
+		out.Body = &body
+	} else {
+		out.BodyBase64 = d.Body
+	}
+	return json.Marshal(out)
+}
+
+func (d *Delivery) UnmarshalJSON(b []byte) error {
+	var in deliveryJSON
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+	*d = Delivery{
+		ReceivedAt: in.ReceivedAt,
+		Method:     in.Method,
+		Path:       in.Path,
+		Header:     in.Header,
+		Body:       in.BodyBase64,
+	}
+	if in.Body != nil {
+		d.Body = []byte(*in.Body)
+	}
+	return nil
+}
+
+type deliveryKey struct{}
+
+// DeliveryFrom returns the verified delivery of a request handled behind
+// ReceiverMiddleware
+func DeliveryFrom(ctx context.Context) (*Delivery, bool) {
+	d, ok := ctx.Value(deliveryKey{}).(*Delivery)
+	return d, ok
+}
+
+// ReceiverOption configures ReceiverMiddleware
+type ReceiverOption func(*receiver)
+
+// WithMaxBodySize limits the size of delivery bodies. Larger deliveries are
+// rejected with 413.
+func WithMaxBodySize(n int64) ReceiverOption {
+	return func(r *receiver) {
+		r.maxBodySize = n
+	}
+}
+
+// WithReceiverMetrics reports each verification to m
+func WithReceiverMetrics(m Metrics) ReceiverOption {
+	return func(r *receiver) {
+		r.metrics = m
+	}
+}
+
+// OnDelivery calls fn with every verified delivery before the next handler
+// runs. Use it to log or record deliveries.
+func OnDelivery(fn func(*Delivery)) ReceiverOption {
+	return func(r *receiver) {
+		r.onDelivery = append(r.onDelivery, fn)
+	}
+}
+
+// OnReject calls fn with every delivery that fails verification and the
+// reason it was rejected
+func OnReject(fn func(*Delivery, error)) ReceiverOption {
+	return func(r *receiver) {
+		r.onReject = append(r.onReject, fn)
+	}
+}
+
+type receiver struct {
+	secret      string
+	maxBodySize int64
+	metrics     Metrics
+	onDelivery  []func(*Delivery)
+	onReject    []func(*Delivery, error)
+}
+
+// ReceiverMiddleware verifies the signature of incoming webhook deliveries
+// with secret. Deliveries that fail verification are rejected with 401;
+// verified ones reach next with the body intact and the Delivery available
+// through DeliveryFrom.
+func ReceiverMiddleware(secret string, opts ...ReceiverOption) func(http.Handler) http.Handler {
+	rc := &receiver{secret: secret, maxBodySize: DefaultMaxBodySize}
+	for _, opt := range opts {
+		opt(rc)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d, err := rc.read(r)
+			if err != nil {
+				status := http.StatusBadRequest
+				if errors.Is(err, ErrBodyTooLarge) {
+					status = http.StatusRequestEntityTooLarge
+				}
+				rc.reject(d, err)
+				http.Error(w, err.Error(), status)
+				return
+			}
+
+			_, err = verify(d.Body, d.Signature(), rc.secret)
+			if rc.metrics != nil {
+				rc.metrics.ObserveVerification(err == nil)
+			}
+			if err != nil {
+				rc.reject(d, err)
+				http.Error(w, "invalid webhook signature", http.StatusUnauthorized)
+				return
+			}
+
+			for _, fn := range rc.onDelivery {
+				fn(d)
+			}
+			r.Body = io.NopCloser(bytes.NewReader(d.Body))
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), deliveryKey{}, d)))
+		})
+	}
+}
+
+func (rc *receiver) read(r *http.Request) (*Delivery, error) {
+	d := &Delivery{
+		ReceivedAt: time.Now().UTC(),
+		Method:     r.Method,
+		Path:       r.URL.RequestURI(),
+		Header:     r.Header.Clone(),
+	}
+	if r.Body == nil {
+		return d, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, rc.maxBodySize+1))
+	if err != nil {
+		return d, fmt.Errorf("vartiq: failed to read webhook body: %w", err)
+	}
+	if int64(len(body)) > rc.maxBodySize {
+		return d, ErrBodyTooLarge
+	}
+	d.Body = body
+	return d, nil
+}
+
+func (rc *receiver) reject(d *Delivery, err error) {
+	for _, fn := range rc.onReject {
+		fn(d, err)
+	}
+}
@@ -0,0 +1,88 @@
+package vartiq
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signPayload(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func signedRequest(body, secret string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/hooks?source=vartiq", strings.NewReader(body))
+	req.Header.Set(SignatureHeader, signPayload([]byte(body), secret))
+	return req
+}
+
+func TestReceiverMiddleware(t *testing.T) {
+	var delivered []*Delivery
+	var rejected []error
+	m := &recordingMetrics{}
+	mw := ReceiverMiddleware("secret",
+		OnDelivery(func(d *Delivery) { delivered = append(delivered, d) }),
+		OnReject(func(d *Delivery, err error) { rejected = append(rejected, err) }),
+		WithReceiverMetrics(m),
+	)
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		d, ok := DeliveryFrom(r.Context())
+		require.True(t, ok)
+		assert.Equal(t, d.Body, body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedRequest(`{"hello":"world"}`, "secret"))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	require.Len(t, delivered, 1)
+	assert.Equal(t, "/hooks?source=vartiq", delivered[0].Path)
+	assert.Equal(t, `{"hello":"world"}`, string(delivered[0].Body))
+	assert.NotEmpty(t, delivered[0].Signature())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedRequest(`{"hello":"world"}`, "other"))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Len(t, rejected, 1)
+	assert.Len(t, delivered, 1)
+	assert.Equal(t, []bool{true, false}, m.verifications)
+}
+
+func TestReceiverMiddleware_MaxBodySize(t *testing.T) {
+	handler := ReceiverMiddleware("secret", WithMaxBodySize(4))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler called")
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedRequest(`{"too":"large"}`, "secret"))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestDelivery_JSON(t *testing.T) {
+	for _, body := range [][]byte{[]byte(`{"a":1}`), {0xff, 0x00, 0x10}} {
+		d := Delivery{Method: "POST", Path: "/", Header: http.Header{"X-Test": {"1"}}, Body: body}
+		b, err := json.Marshal(d)
+		require.NoError(t, err)
+
+		var decoded Delivery
+		require.NoError(t, json.Unmarshal(b, &decoded))
+		assert.Equal(t, d.Body, decoded.Body)
+		assert.Equal(t, d.Header, decoded.Header)
+	}
+
+	b, err := json.Marshal(Delivery{Body: []byte(`{"a":1}`)})
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"body":"{\"a\":1}"`)
+}
@@ -0,0 +1,192 @@
+package vartiq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Region selects the Vartiq data region a client talks to
+type Region string
+
+const (
+	// RegionUS is the default region, https://api.us.vartiq.com
+	RegionUS Region = "us"
+	// RegionEU keeps data in the European Union, https://api.eu.vartiq.com
+	RegionEU Region = "eu"
+)
+
+// RegionEnvVar is the environment variable read when no region or base URL
+// is configured
+const RegionEnvVar = "VARTIQ_REGION"
+
+// ErrProjectNotFound is returned by MultiRegionClient when a project does
+// not exist in any of its regions
+var ErrProjectNotFound = errors.New("vartiq: project not found in any region")
+
+// ParseRegion validates a region name or custom endpoint URL
+func ParseRegion(s string) (Region, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "://") {
+		return Region(strings.TrimRight(s, "/")), nil
+	}
+	switch r := Region(strings.ToLower(s)); r {
+	case RegionUS, RegionEU:
+		return r, nil
+	}
+	return "", fmt.Errorf("vartiq: unknown region %q", s)
+}
+
+// BaseURL returns the API endpoint of the region. A region may also be a
+// full URL, which is used as is for custom deployments.
+func (r Region) BaseURL() string {
+	if strings.Contains(string(r), "://") {
+		return string(r)
+	}
+	return "https://api." + strings.ToLower(string(r)) + ".vartiq.com"
+}
+
+// WithRegion sends requests to the endpoint of region r. WithBaseURL takes
+// precedence over WithRegion.
+func WithRegion(r Region) Option {
+	return func(c *Client) {
+		c.region = r
+	}
+}
+
+// RegionFromEnv returns the region named by VARTIQ_REGION, or "" when it is
+// not set. Clients ignore an invalid value and use the US region; call
+// RegionFromEnv first to reject it instead.
+func RegionFromEnv() (Region, error) {
+	env := os.Getenv(RegionEnvVar)
+	if env == "" {
+		return "", nil
+	}
+	r, err := ParseRegion(env)
+	if err != nil {
+		return "", fmt.Errorf("vartiq: invalid %s: %w", RegionEnvVar, err)
+	}
+	return r, nil
+}
+
+// resolveRegion picks the region from the options, then the environment,
+// falling back to RegionUS. An explicit base URL is reported as a custom
+// region.
+func (c *Client) resolveRegion() {
+	if c.baseURL != "" {
+		if c.region == "" || c.region.BaseURL() != c.baseURL {
+			c.region = Region(strings.TrimRight(c.baseURL, "/"))
+		}
+		return
+	}
+	if c.region == "" {
+		c.region, _ = RegionFromEnv()
+	}
+	if c.region == "" {
+		c.region = RegionUS
+	}
+	c.baseURL = c.region.BaseURL()
+}
+
+// Region returns the region the client was configured for. For a client
+// created with a custom base URL it is that URL.
+func (c *Client) Region() Region {
+	return c.region
+}
+
+// MultiRegionClient routes calls to the region that holds each project.
+// Clients for each region are created on first use and share the options
+// given to NewMultiRegion.
+type MultiRegionClient struct {
+	apiKey  string
+	regions []Region
+	opts    []Option
+
+	mu       sync.Mutex
+	clients  map[Region]*Client
+	projects map[string]Region
+}
+
+// NewMultiRegion creates a MultiRegionClient. regions is the order in which
+// regions are searched for projects whose region is not known yet; it
+// defaults to all known regions.
+func NewMultiRegion(apiKey string, regions []Region, opts ...Option) *MultiRegionClient {
+	if len(regions) == 0 {
+		regions = []Region{RegionUS, RegionEU}
+	}
+	return &MultiRegionClient{
+		apiKey:   apiKey,
+		regions:  regions,
+		opts:     opts,
+		clients:  make(map[Region]*Client),
+		projects: make(map[string]Region),
+	}
+}
+
+// Region returns the client for region r
+func (m *MultiRegionClient) Region(r Region) *Client {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok := m.clients[r]; ok {
+		return c
+	}
+	opts := append(append([]Option(nil), m.opts...), WithRegion(r), WithBaseURL(r.BaseURL()))
+	c := NewWithOptions(m.apiKey, opts...)
+	m.clients[r] = c
+	return c
+}
+
+// SetProjectRegion records which region holds a project
+func (m *MultiRegionClient) SetProjectRegion(projectID string, r Region) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.projects[projectID] = r
+}
+
+// ProjectRegion returns the recorded region of a project
+func (m *MultiRegionClient) ProjectRegion(projectID string) (Region, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.projects[projectID]
+	return r, ok
+}
+
+// ForProject returns the client for the region that holds projectID. If the
+// region is not known yet, each region is asked for the project in turn and
+// the answer is remembered. A region that cannot be reached is skipped; if
+// no region has the project, the errors of the skipped regions are returned
+// with ErrProjectNotFound.
+func (m *MultiRegionClient) ForProject(ctx context.Context, projectID string) (*Client, error) {
+	if r, ok := m.ProjectRegion(projectID); ok {
+		return m.Region(r), nil
+	}
+	var errs []error
+	for _, r := range m.regions {
+		c := m.Region(r)
+		resp, err := c.Project.Get(ctx, projectID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("region %s: %w", r, err))
+			continue
+		}
+		if resp.Success && resp.Data.ID != "" {
+			m.SetProjectRegion(projectID, r)
+			return c, nil
+		}
+	}
+	return nil, errors.Join(append([]error{ErrProjectNotFound}, errs...)...)
+}
+
+// CreateProject creates a project in region r and records its region
+func (m *MultiRegionClient) CreateProject(ctx context.Context, r Region, req *CreateProjectRequest) (*CreateProjectResponse, error) {
+	resp, err := m.Region(r).Project.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Data.ID != "" {
+		m.SetProjectRegion(resp.Data.ID, r)
+	}
+	return resp, nil
+}
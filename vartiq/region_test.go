@@ -0,0 +1,142 @@
+package vartiq
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRegion(t *testing.T) {
+	r, err := ParseRegion("EU")
+	require.NoError(t, err)
+	assert.Equal(t, RegionEU, r)
+
+	r, err = ParseRegion("https://vartiq.internal.example.com/")
+	require.NoError(t, err)
+	assert.Equal(t, "https://vartiq.internal.example.com", r.BaseURL())
+
+	_, err = ParseRegion("mars")
+	assert.Error(t, err)
+}
+
+func TestRegion_BaseURL(t *testing.T) {
+	assert.Equal(t, "https://api.us.vartiq.com", RegionUS.BaseURL())
+	assert.Equal(t, "https://api.eu.vartiq.com", RegionEU.BaseURL())
+}
+
+func TestNewWithOptions_Region(t *testing.T) {
+	t.Setenv(RegionEnvVar, "")
+
+	client := NewWithOptions("test-key")
+	assert.Equal(t, RegionUS, client.Region())
+	assert.Equal(t, "https://api.us.vartiq.com", client.baseURL)
+
+	client = NewWithOptions("test-key", WithRegion(RegionEU))
+	assert.Equal(t, RegionEU, client.Region())
+	assert.Equal(t, "https://api.eu.vartiq.com", client.baseURL)
+
+	// An explicit base URL wins over the region
+	client = NewWithOptions("test-key", WithRegion(RegionEU), WithBaseURL("https://custom.example.com"))
+	assert.Equal(t, "https://custom.example.com", client.baseURL)
+	assert.Equal(t, Region("https://custom.example.com"), client.Region())
+}
+
+func TestNewWithOptions_RegionFromEnv(t *testing.T) {
+	t.Setenv(RegionEnvVar, "eu")
+	assert.Equal(t, "https://api.eu.vartiq.com", New("test-key").baseURL)
+	assert.Equal(t, "https://api.us.vartiq.com", NewWithOptions("test-key", WithRegion(RegionUS)).baseURL)
+
+	r, err := RegionFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, RegionEU, r)
+
+	// An invalid region is ignored by clients and reported by RegionFromEnv
+	t.Setenv(RegionEnvVar, "nowhere")
+	client := New("test-key")
+	assert.Equal(t, RegionUS, client.Region())
+	assert.Equal(t, "https://api.us.vartiq.com", client.baseURL)
+	_, err = RegionFromEnv()
+	assert.ErrorContains(t, err, "invalid VARTIQ_REGION")
+
+	// the environment is not consulted when a base URL is given
+	client = New("test-key", "https://custom.example.com")
+	assert.Equal(t, "https://custom.example.com", client.baseURL)
+}
+
+func TestMultiRegionClient_ForProject(t *testing.T) {
+	var usCalls, euCalls int
+	us := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		usCalls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"not found","success":false}`))
+	}))
+	defer us.Close()
+	eu := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		euCalls++
+		w.Header().Set("Content-Type", "application/json")
+		id := strings.TrimPrefix(r.URL.Path, "/projects/")
+		if id != "project-eu" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"not found","success":false}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"id":"project-eu"},"success":true}`))
+	}))
+	defer eu.Close()
+
+	m := NewMultiRegion("test-key", []Region{Region(us.URL), Region(eu.URL)})
+	ctx := context.Background()
+
+	client, err := m.ForProject(ctx, "project-eu")
+	require.NoError(t, err)
+	assert.Equal(t, eu.URL, client.baseURL)
+	r, ok := m.ProjectRegion("project-eu")
+	assert.True(t, ok)
+	assert.Equal(t, Region(eu.URL), r)
+
+	// The region is remembered
+	_, err = m.ForProject(ctx, "project-eu")
+	require.NoError(t, err)
+	assert.Equal(t, 1, usCalls)
+	assert.Equal(t, 1, euCalls)
+
+	_, err = m.ForProject(ctx, "project-missing")
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+
+	m.SetProjectRegion("project-us", Region(us.URL))
+	client, err = m.ForProject(ctx, "project-us")
+	require.NoError(t, err)
+	assert.Same(t, m.Region(Region(us.URL)), client)
+}
+
+func TestMultiRegionClient_ForProjectSkipsUnreachableRegions(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	eu := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/projects/project-eu" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"not found","success":false}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"id":"project-eu"},"success":true}`))
+	}))
+	defer eu.Close()
+
+	m := NewMultiRegion("test-key", []Region{Region(down.URL), Region(eu.URL)})
+	ctx := context.Background()
+
+	client, err := m.ForProject(ctx, "project-eu")
+	require.NoError(t, err)
+	assert.Equal(t, eu.URL, client.baseURL)
+
+	_, err = m.ForProject(ctx, "project-missing")
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+	assert.ErrorContains(t, err, "region "+down.URL)
+}
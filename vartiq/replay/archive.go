@@ -0,0 +1,115 @@
+// Package replay records verified webhook deliveries to a JSONL archive and
+// replays them against any URL, for reproducing production bugs locally.
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+// Archive appends deliveries to a file, one JSON object per line. It is safe
+// for concurrent use.
+type Archive struct {
+	mu   sync.Mutex
+	file *os.File
+	err  error
+}
+
+// OpenArchive opens the archive at path for appending, creating it if needed
+func OpenArchive(path string) (*Archive, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("replay: failed to open archive: %w", err)
+	}
+	return &Archive{file: f}, nil
+}
+
+// Record appends a delivery to the archive
+func (a *Archive) Record(d *vartiq.Delivery) error {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("replay: failed to encode delivery: %w", err)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.file.Write(append(b, '\n')); err != nil {
+		if a.err == nil {
+			a.err = err
+		}
+		return fmt.Errorf("replay: failed to write delivery: %w", err)
+	}
+	return nil
+}
+
+// ReceiverOption records every delivery verified by vartiq.ReceiverMiddleware.
+// Write errors do not fail the delivery; they are reported by Err.
+func (a *Archive) ReceiverOption() vartiq.ReceiverOption {
+	return vartiq.OnDelivery(func(d *vartiq.Delivery) {
+		a.Record(d)
+	})
+}
+
+// Err returns the first error that occurred while recording
+func (a *Archive) Err() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
+
+// Close closes the archive file
+func (a *Archive) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.file.Close()
+}
+
+// Load reads the deliveries of an archive. Blank lines are skipped, and a
+// torn final line, left by a crash during a write, is ignored.
+func Load(r io.Reader) ([]vartiq.Delivery, error) {
+	var out []vartiq.Delivery
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 && !isBlank(line) {
+			var d vartiq.Delivery
+			if jerr := json.Unmarshal(line, &d); jerr != nil {
+				if err == io.EOF {
+					return out, nil
+				}
+				return nil, fmt.Errorf("replay: line %d: %w", n, jerr)
+			}
+			out = append(out, d)
+		}
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// LoadFile reads the deliveries of the archive at path
+func LoadFile(path string) ([]vartiq.Delivery, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("replay: failed to open archive: %w", err)
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+func isBlank(b []byte) bool {
+	for _, c := range b {
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package replay
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+func TestArchive_RecordFromReceiver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deliveries.jsonl")
+	archive, err := OpenArchive(path)
+	require.NoError(t, err)
+
+	handler := vartiq.ReceiverMiddleware("secret", archive.ReceiverOption())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, body := range []string{`{"type":"order.created"}`, `{"type":"order.paid"}`} {
+		req := httptest.NewRequest(http.MethodPost, "/hooks", strings.NewReader(body))
+		req.Header.Set(vartiq.SignatureHeader, sign([]byte(body), "secret"))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/hooks", strings.NewReader(`{"forged":true}`))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.NoError(t, archive.Err())
+	require.NoError(t, archive.Close())
+
+	deliveries, err := LoadFile(path)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, `{"type":"order.paid"}`, string(deliveries[1].Body))
+	assert.Equal(t, "order.paid", EventType(&deliveries[1]))
+	assert.False(t, deliveries[0].ReceivedAt.IsZero())
+}
+
+func TestLoad_TornLine(t *testing.T) {
+	data := `{"receivedAt":"2024-01-01T00:00:00Z","header":{},"body":"a"}` + "\n\n" + `{"receivedAt":"2024-01-0`
+	deliveries, err := Load(strings.NewReader(data))
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+
+	_, err = Load(strings.NewReader("{broken\n" + data))
+	assert.Error(t, err)
+}
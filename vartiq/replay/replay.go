@@ -0,0 +1,193 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+// ReplayHeader is set on every replayed request to the time of the original
+// delivery, so receivers can tell replays apart
+const ReplayHeader = "X-Vartiq-Replay-Of"
+
+// EventTypeHeader carries the event type of a delivery when the sender sets it
+const EventTypeHeader = "X-Vartiq-Event-Type"
+
+// Filter selects the deliveries to replay. Zero fields match everything.
+type Filter struct {
+	// EventTypes matches deliveries whose EventType is one of the list
+	EventTypes []string
+	// From and To match deliveries received in [From, To)
+	From time.Time
+	To   time.Time
+}
+
+// Match reports whether d is selected by the filter
+func (f Filter) Match(d *vartiq.Delivery) bool {
+	if !f.From.IsZero() && d.ReceivedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !d.ReceivedAt.Before(f.To) {
+		return false
+	}
+	if len(f.EventTypes) == 0 {
+		return true
+	}
+	eventType := EventType(d)
+	for _, t := range f.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// EventType returns the event type of a delivery: the event type header if
+// present, otherwise the "eventType", "type" or "event" field of a JSON body
+func EventType(d *vartiq.Delivery) string {
+	if t := d.Header.Get(EventTypeHeader); t != "" {
+		return t
+	}
+	var body map[string]interface{}
+	if json.Unmarshal(d.Body, &body) != nil {
+		return ""
+	}
+	for _, key := range []string{"eventType", "type", "event"} {
+		if t, ok := body[key].(string); ok {
+			return t
+		}
+	}
+	return ""
+}
+
+// Option configures a Replayer
+type Option func(*Replayer)
+
+// WithSecret re-signs each delivery with secret instead of sending its
+// original signature, for targets configured with a different secret
+func WithSecret(secret string) Option {
+	return func(r *Replayer) {
+		r.secret = secret
+	}
+}
+
+// WithRateLimit sends at most rps deliveries per second. rps <= 0 means no
+// limit.
+func WithRateLimit(rps float64) Option {
+	return func(r *Replayer) {
+		r.limiter = vartiq.NewRateLimiter(rps, 1)
+	}
+}
+
+// WithFilter replays only the deliveries matched by f
+func WithFilter(f Filter) Option {
+	return func(r *Replayer) {
+		r.filter = f
+	}
+}
+
+// WithHTTPClient sets the client used to send deliveries
+func WithHTTPClient(c *http.Client) Option {
+	return func(r *Replayer) {
+		r.client = c
+	}
+}
+
+// Result is the outcome of replaying one delivery
+type Result struct {
+	Delivery   vartiq.Delivery
+	StatusCode int
+	Err        error
+}
+
+// Replayer re-sends recorded deliveries to a target URL
+type Replayer struct {
+	target  string
+	secret  string
+	limiter *vartiq.RateLimiter
+	filter  Filter
+	client  *http.Client
+}
+
+// NewReplayer creates a Replayer that sends deliveries to target
+func NewReplayer(target string, opts ...Option) *Replayer {
+	r := &Replayer{target: target, client: http.DefaultClient}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Replay sends the deliveries matched by the filter in order. A delivery that
+// fails does not stop the ones after it; the returned error is only set when
+// ctx is done.
+func (r *Replayer) Replay(ctx context.Context, deliveries []vartiq.Delivery) ([]Result, error) {
+	var results []Result
+	for i := range deliveries {
+		d := &deliveries[i]
+		if !r.filter.Match(d) {
+			continue
+		}
+		if r.limiter != nil {
+			if err := r.limiter.Wait(ctx); err != nil {
+				return results, err
+			}
+		}
+		status, err := r.Send(ctx, d)
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		results = append(results, Result{Delivery: *d, StatusCode: status, Err: err})
+	}
+	return results, nil
+}
+
+// Send replays a single delivery and returns the response status. It is not
+// subject to the filter or rate limit.
+func (r *Replayer) Send(ctx context.Context, d *vartiq.Delivery) (int, error) {
+	method := d.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, r.target, bytes.NewReader(d.Body))
+	if err != nil {
+		return 0, fmt.Errorf("replay: %w", err)
+	}
+	req.Header = d.Header.Clone()
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	for _, h := range []string{"Content-Length", "Host", "Accept-Encoding", "Connection"} {
+		req.Header.Del(h)
+	}
+	if r.secret != "" {
+		req.Header.Set(vartiq.SignatureHeader, sign(d.Body, r.secret))
+	}
+	req.Header.Set(ReplayHeader, d.ReceivedAt.UTC().Format(time.RFC3339Nano))
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("replay: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("replay: target answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+func sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,112 @@
+package replay
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+func delivery(body string, at time.Time, secret string) vartiq.Delivery {
+	return vartiq.Delivery{
+		ReceivedAt: at,
+		Method:     http.MethodPost,
+		Path:       "/hooks",
+		Header: http.Header{
+			"Content-Type":         {"application/json"},
+			vartiq.SignatureHeader: {sign([]byte(body), secret)},
+		},
+		Body: []byte(body),
+	}
+}
+
+func TestFilter(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := delivery(`{"eventType":"user.deleted"}`, base, "s")
+
+	assert.True(t, Filter{}.Match(&d))
+	assert.True(t, Filter{EventTypes: []string{"user.created", "user.deleted"}}.Match(&d))
+	assert.False(t, Filter{EventTypes: []string{"user.created"}}.Match(&d))
+	assert.True(t, Filter{From: base, To: base.Add(time.Second)}.Match(&d))
+	assert.False(t, Filter{To: base}.Match(&d))
+	assert.False(t, Filter{From: base.Add(time.Second)}.Match(&d))
+
+	d.Header.Set(EventTypeHeader, "from.header")
+	assert.Equal(t, "from.header", EventType(&d))
+}
+
+func TestReplayer(t *testing.T) {
+	var mu sync.Mutex
+	var received []*http.Request
+	var bodies []string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, r)
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		if strings.Contains(string(body), "fail") {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer target.Close()
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	deliveries := []vartiq.Delivery{
+		delivery(`{"type":"a"}`, base, "prod"),
+		delivery(`{"type":"b"}`, base.Add(time.Minute), "prod"),
+		delivery(`{"type":"a","fail":true}`, base.Add(2*time.Minute), "prod"),
+	}
+
+	results, err := NewReplayer(target.URL, WithFilter(Filter{EventTypes: []string{"a"}})).Replay(context.Background(), deliveries)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, http.StatusOK, results[0].StatusCode)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, http.StatusInternalServerError, results[1].StatusCode)
+	assert.Error(t, results[1].Err)
+
+	assert.Equal(t, []string{`{"type":"a"}`, `{"type":"a","fail":true}`}, bodies)
+	assert.Equal(t, sign([]byte(`{"type":"a"}`), "prod"), received[0].Header.Get(vartiq.SignatureHeader))
+	assert.Equal(t, "2024-01-01T00:00:00Z", received[0].Header.Get(ReplayHeader))
+	assert.Equal(t, "application/json", received[0].Header.Get("Content-Type"))
+
+	_, err = NewReplayer(target.URL, WithSecret("local")).Send(context.Background(), &deliveries[0])
+	require.NoError(t, err)
+	_, err = vartiq.New("").Verify([]byte(bodies[2]), received[2].Header.Get(vartiq.SignatureHeader), "local")
+	assert.NoError(t, err)
+}
+
+func TestReplayer_RateLimit(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+
+	deliveries := []vartiq.Delivery{delivery(`{}`, time.Now(), "s"), delivery(`{}`, time.Now(), "s")}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	results, err := NewReplayer(target.URL, WithRateLimit(1)).Replay(ctx, deliveries)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Len(t, results, 1)
+}
+
+func TestReplayer_NoRateLimit(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+
+	deliveries := []vartiq.Delivery{delivery(`{}`, time.Now(), "s"), delivery(`{}`, time.Now(), "s")}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	results, err := NewReplayer(target.URL, WithRateLimit(0)).Replay(ctx, deliveries)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+}
@@ -0,0 +1,94 @@
+package vartiq
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// RetryConfig configures how the client retries requests that failed with a
+// network error or a 5xx response. Only idempotent methods are retried
+// unless RetryPost is set, because a failed POST may still have been applied.
+type RetryConfig struct {
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int `json:"maxRetries"`
+	// WaitTime is the delay before the first retry; it doubles for each
+	// further retry (default 100ms)
+	WaitTime Duration `json:"waitTime"`
+	// MaxWaitTime caps the delay between retries (default 2s)
+	MaxWaitTime Duration `json:"maxWaitTime"`
+	// RetryPost also retries POST requests, such as message sends
+	RetryPost bool `json:"retryPost"`
+}
+
+// WithRetry makes the client retry failed requests
+func WithRetry(cfg RetryConfig) Option {
+	return func(c *Client) {
+		if cfg.WaitTime <= 0 {
+			cfg.WaitTime = Duration(100 * time.Millisecond)
+		}
+		if cfg.MaxWaitTime < cfg.WaitTime {
+			cfg.MaxWaitTime = Duration(2 * time.Second)
+		}
+		c.retry = &cfg
+	}
+}
+
+// WithTimeout limits how long a single API call may take
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// retryTransport retries requests that failed with a network error or a
+// 5xx response, waiting with exponential backoff between attempts
+type retryTransport struct {
+	client *Client
+	cfg    RetryConfig
+	base   http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if req.Method == http.MethodPost && !t.cfg.RetryPost {
+		return t.base.RoundTrip(req)
+	}
+
+	wait := time.Duration(t.cfg.WaitTime)
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if ctx.Err() != nil || attempt >= t.cfg.MaxRetries || !shouldRetry(resp, err) {
+			return resp, err
+		}
+		retry, ok := rewind(req)
+		if !ok {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		wait *= 2
+		if wait > time.Duration(t.cfg.MaxWaitTime) {
+			wait = time.Duration(t.cfg.MaxWaitTime)
+		}
+		t.client.countRetry(ctx)
+		req = retry
+	}
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= 500
+}
@@ -0,0 +1,74 @@
+package vartiq
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_WithRetry(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"id":"project-1"},"success":true}`))
+	}))
+	defer server.Close()
+
+	m := &recordingMetrics{}
+	client := NewWithOptions("test-key",
+		WithBaseURL(server.URL),
+		WithRetry(RetryConfig{MaxRetries: 3, WaitTime: Duration(time.Millisecond)}),
+		WithMetrics(m),
+	)
+
+	resp, err := client.Project.Get(context.Background(), "project-1")
+	require.NoError(t, err)
+	assert.Equal(t, "project-1", resp.Data.ID)
+	assert.Equal(t, 3, calls)
+	assert.Len(t, m.retries, 2)
+}
+
+func TestClient_WithRetry_SkipsPost(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewWithOptions("test-key",
+		WithBaseURL(server.URL),
+		WithRetry(RetryConfig{MaxRetries: 3, WaitTime: Duration(time.Millisecond)}),
+	)
+	_, _ = client.Project.Create(context.Background(), &CreateProjectRequest{Name: "Test"})
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	client = NewWithOptions("test-key",
+		WithBaseURL(server.URL),
+		WithRetry(RetryConfig{MaxRetries: 2, WaitTime: Duration(time.Millisecond), RetryPost: true}),
+	)
+	_, _ = client.Project.Create(context.Background(), &CreateProjectRequest{Name: "Test"})
+	assert.Equal(t, 3, calls)
+}
+
+func TestClient_WithTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := NewWithOptions("test-key", WithBaseURL(server.URL), WithTimeout(10*time.Millisecond))
+	_, err := client.Project.List(context.Background())
+	assert.Error(t, err)
+}
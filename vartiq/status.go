@@ -0,0 +1,73 @@
+package vartiq
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+)
+
+type statusKey struct{}
+
+// responseStatus is the last response recorded for a context
+type responseStatus struct {
+	mu      sync.Mutex
+	code    int
+	message string
+}
+
+// WithResponseStatus returns a context that records the status code and
+// error message of the responses to requests made with it. Most methods
+// report an error response through the Success field of their result rather
+// than an error; pass the same context to CheckResponse to get an *APIError
+// with the status code. Requests made concurrently with one context
+// overwrite each other's status.
+func WithResponseStatus(ctx context.Context) context.Context {
+	return context.WithValue(ctx, statusKey{}, &responseStatus{})
+}
+
+// CheckResponse returns nil if success is true and the last response
+// recorded by ctx was not an error. Otherwise it returns an *APIError with
+// the status code of that response. message, typically the Message of the
+// result, takes precedence over the message of the error response.
+func CheckResponse(ctx context.Context, success bool, message string) error {
+	var code int
+	if s, ok := ctx.Value(statusKey{}).(*responseStatus); ok {
+		s.mu.Lock()
+		code = s.code
+		if message == "" {
+			message = s.message
+		}
+		s.mu.Unlock()
+	}
+	if success && code < 400 {
+		return nil
+	}
+	if message == "" {
+		message = http.StatusText(code)
+	}
+	if message == "" {
+		message = "request was not successful"
+	}
+	return &APIError{Message: message, Code: code}
+}
+
+// recordStatus stores the status of resp in the context of its request
+func recordStatus(_ *resty.Client, resp *resty.Response) error {
+	s, ok := resp.Request.Context().Value(statusKey{}).(*responseStatus)
+	if !ok {
+		return nil
+	}
+	var body struct {
+		Message string `json:"message"`
+	}
+	if resp.IsError() {
+		json.Unmarshal(resp.Body(), &body)
+	}
+	s.mu.Lock()
+	s.code, s.message = resp.StatusCode(), body.Message
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,49 @@
+package vartiq
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/internal/apitest"
+)
+
+func TestCheckResponse(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	projectID := api.Add(apitest.Projects, apitest.Object{"name": "shop"})
+	ctx := WithResponseStatus(context.Background())
+
+	resp, err := client.Project.Get(ctx, projectID)
+	require.NoError(t, err)
+	assert.NoError(t, CheckResponse(ctx, resp.Success, resp.Message))
+
+	resp, err = client.Project.Get(ctx, "missing")
+	require.NoError(t, err)
+	err = CheckResponse(ctx, resp.Success, resp.Message)
+	assert.Equal(t, &APIError{Message: "project not found", Code: http.StatusNotFound}, err)
+
+	require.NoError(t, client.Project.Delete(ctx, "missing"))
+	assert.Equal(t, ErrorClassNotFound, ClassifyError(CheckResponse(ctx, true, "")))
+
+	// Without a recording context only the message is known
+	err = CheckResponse(context.Background(), false, "")
+	assert.Equal(t, &APIError{Message: "request was not successful"}, err)
+}
+
+func TestUnsuccessful_HasStatusCode(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	ctx := context.Background()
+
+	_, err := client.Project.Export(ctx, "missing")
+	assert.Equal(t, ErrorClassNotFound, ClassifyError(err))
+
+	api.FailNext("GET /projects", http.StatusTooManyRequests)
+	_, _, err = client.Project.Ensure(ctx, &CreateProjectRequest{Name: "shop"})
+	assert.Equal(t, ErrorClassRateLimited, ClassifyError(err))
+}
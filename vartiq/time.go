@@ -0,0 +1,159 @@
+package vartiq
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayouts are the timestamp formats the API has been seen to return
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// Time is a timestamp returned by the API. It decodes RFC 3339 strings with
+// or without a zone or fractional seconds, space-separated date times, and
+// Unix timestamps in seconds or milliseconds. Empty strings and null decode
+// to the zero time. Timestamps without a zone are treated as UTC.
+//
+// Time embeds time.Time, so code that used the former string fields can call
+// String() to get the RFC 3339 form or use the time.Time methods directly.
+type Time struct {
+	time.Time
+}
+
+// ParseTime parses a timestamp in any of the formats accepted by Time
+func ParseTime(s string) (Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "null" {
+		return Time{}, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return unixTime(n), nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return Time{t}, nil
+		}
+	}
+	return Time{}, fmt.Errorf("vartiq: unrecognized timestamp %q", s)
+}
+
+// unixTime interprets n as seconds, or milliseconds when it is too large to
+// be a plausible number of seconds
+func unixTime(n int64) Time {
+	if n == 0 {
+		return Time{}
+	}
+	if n > 1e11 || n < -1e11 {
+		return Time{time.UnixMilli(n).UTC()}
+	}
+	return Time{time.Unix(n, 0).UTC()}
+}
+
+func (t *Time) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] != '"' {
+		if string(b) == "null" {
+			*t = Time{}
+			return nil
+		}
+		var n json.Number
+		if err := json.Unmarshal(b, &n); err != nil {
+			return fmt.Errorf("vartiq: unrecognized timestamp %s", b)
+		}
+		i, err := n.Int64()
+		if err != nil {
+			f, ferr := n.Float64()
+			if ferr != nil {
+				return fmt.Errorf("vartiq: unrecognized timestamp %s", b)
+			}
+			i = int64(f)
+		}
+		*t = unixTime(i)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseTime(s)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// MarshalJSON encodes the time as an RFC 3339 string, or null when it is zero
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.Time.Format(time.RFC3339Nano))
+}
+
+// String returns the time in RFC 3339 format, or "" when it is zero
+func (t Time) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Time.Format(time.RFC3339Nano)
+}
+
+// Timestamped is implemented by Project, App, Webhook and WebhookMessage
+type Timestamped interface {
+	Created() time.Time
+	Updated() time.Time
+}
+
+// SortByCreatedAt sorts items from oldest to newest creation time
+func SortByCreatedAt[T Timestamped](items []T) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Created().Before(items[j].Created())
+	})
+}
+
+// SortByUpdatedAt sorts items from least to most recently updated
+func SortByUpdatedAt[T Timestamped](items []T) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Updated().Before(items[j].Updated())
+	})
+}
+
+// CreatedBetween returns the items created in [from, to). A zero from or to
+// leaves that end of the range open.
+func CreatedBetween[T Timestamped](items []T, from, to time.Time) []T {
+	var out []T
+	for _, item := range items {
+		created := item.Created()
+		if !from.IsZero() && created.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !created.Before(to) {
+			continue
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+// UpdatedSince returns the items updated at or after since
+func UpdatedSince[T Timestamped](items []T, since time.Time) []T {
+	var out []T
+	for _, item := range items {
+		if !item.Updated().Before(since) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
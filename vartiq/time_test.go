@@ -0,0 +1,94 @@
+package vartiq
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTime_UnmarshalJSON(t *testing.T) {
+	want := time.Date(2024, 5, 1, 12, 30, 45, 0, time.UTC)
+	tests := []struct {
+		name  string
+		input string
+		want  time.Time
+	}{
+		{"RFC 3339", `"2024-05-01T12:30:45Z"`, want},
+		{"fractional seconds", `"2024-05-01T12:30:45.123Z"`, want.Add(123 * time.Millisecond)},
+		{"offset", `"2024-05-01T14:30:45+02:00"`, want},
+		{"no zone", `"2024-05-01T12:30:45"`, want},
+		{"space separated", `"2024-05-01 12:30:45"`, want},
+		{"unix seconds", `1714566645`, want},
+		{"unix milliseconds", `1714566645000`, want},
+		{"unix seconds string", `"1714566645"`, want},
+		{"empty string", `""`, time.Time{}},
+		{"null", `null`, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Time
+			require.NoError(t, json.Unmarshal([]byte(tt.input), &got))
+			assert.True(t, tt.want.Equal(got.Time), "got %s", got.Time)
+		})
+	}
+}
+
+func TestTime_UnmarshalJSON_Invalid(t *testing.T) {
+	var got Time
+	assert.Error(t, json.Unmarshal([]byte(`"yesterday"`), &got))
+	assert.Error(t, json.Unmarshal([]byte(`true`), &got))
+}
+
+func TestTime_MarshalJSON(t *testing.T) {
+	b, err := json.Marshal(Time{time.Date(2024, 5, 1, 12, 30, 45, 0, time.UTC)})
+	require.NoError(t, err)
+	assert.Equal(t, `"2024-05-01T12:30:45Z"`, string(b))
+
+	b, err = json.Marshal(Time{})
+	require.NoError(t, err)
+	assert.Equal(t, `null`, string(b))
+}
+
+func TestTime_String(t *testing.T) {
+	assert.Equal(t, "2024-05-01T12:30:45Z", Time{time.Date(2024, 5, 1, 12, 30, 45, 0, time.UTC)}.String())
+	assert.Equal(t, "", Time{}.String())
+}
+
+func TestProject_DecodesTimestamps(t *testing.T) {
+	var p Project
+	err := json.Unmarshal([]byte(`{"id":"p1","createdAt":"2024-05-01T12:30:45.000Z","updatedAt":""}`), &p)
+	require.NoError(t, err)
+	assert.Equal(t, 2024, p.CreatedAt.Year())
+	assert.True(t, p.UpdatedAt.IsZero())
+}
+
+func TestTimestampHelpers(t *testing.T) {
+	day := func(d int) Time { return Time{time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC)} }
+	apps := []App{
+		{ID: "c", CreatedAt: day(3), UpdatedAt: day(4)},
+		{ID: "a", CreatedAt: day(1), UpdatedAt: day(9)},
+		{ID: "b", CreatedAt: day(2), UpdatedAt: day(2)},
+	}
+
+	SortByCreatedAt(apps)
+	assert.Equal(t, []string{"a", "b", "c"}, appIDs(apps))
+
+	SortByUpdatedAt(apps)
+	assert.Equal(t, []string{"b", "c", "a"}, appIDs(apps))
+
+	assert.Equal(t, []string{"b", "c"}, appIDs(CreatedBetween(apps, day(2).Time, time.Time{})))
+	assert.Equal(t, []string{"b"}, appIDs(CreatedBetween(apps, day(2).Time, day(3).Time)))
+	assert.Equal(t, []string{"a"}, appIDs(UpdatedSince(apps, day(5).Time)))
+}
+
+func appIDs(apps []App) []string {
+	ids := make([]string, len(apps))
+	for i, app := range apps {
+		ids[i] = app.ID
+	}
+	return ids
+}
@@ -1,5 +1,11 @@
 package vartiq
 
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
 type APIError struct {
 	Message string `json:"message"`
 	Code    int    `json:"code,omitempty"`
@@ -8,3 +14,61 @@ type APIError struct {
 func (e *APIError) Error() string {
 	return e.Message
 }
+
+// ErrorClass groups errors by how a caller should react to them
+type ErrorClass string
+
+const (
+	ErrorClassNone         ErrorClass = ""
+	ErrorClassInvalid      ErrorClass = "invalid_request"
+	ErrorClassUnauthorized ErrorClass = "unauthorized"
+	ErrorClassNotFound     ErrorClass = "not_found"
+	ErrorClassConflict     ErrorClass = "conflict"
+	ErrorClassRateLimited  ErrorClass = "rate_limited"
+	ErrorClassServer       ErrorClass = "server_error"
+	ErrorClassUnavailable  ErrorClass = "unavailable"
+	ErrorClassCanceled     ErrorClass = "canceled"
+	ErrorClassUnknown      ErrorClass = "unknown"
+)
+
+// Class classifies the error by its HTTP status code
+func (e *APIError) Class() ErrorClass {
+	switch {
+	case e.Code == http.StatusUnauthorized, e.Code == http.StatusForbidden:
+		return ErrorClassUnauthorized
+	case e.Code == http.StatusNotFound:
+		return ErrorClassNotFound
+	case e.Code == http.StatusConflict:
+		return ErrorClassConflict
+	case e.Code == http.StatusTooManyRequests:
+		return ErrorClassRateLimited
+	case e.Code >= 500:
+		return ErrorClassServer
+	case e.Code >= 400:
+		return ErrorClassInvalid
+	}
+	return ErrorClassUnknown
+}
+
+// ClassifyError returns the class of an error returned by the client. Errors
+// that are not API errors are classified as unavailable (the API could not
+// be reached or the circuit breaker is open), canceled, or unknown.
+func ClassifyError(err error) ErrorClass {
+	var apiErr *APIError
+	switch {
+	case err == nil:
+		return ErrorClassNone
+	case errors.As(err, &apiErr):
+		return apiErr.Class()
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassCanceled
+	case errors.Is(err, ErrCircuitOpen), isNetworkError(err):
+		return ErrorClassUnavailable
+	}
+	return ErrorClassUnknown
+}
+
+func isNetworkError(err error) bool {
+	var netErr interface{ Timeout() bool }
+	return errors.As(err, &netErr)
+}
@@ -1,6 +1,10 @@
 package vartiq
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,3 +14,29 @@ func TestAPIError_Error(t *testing.T) {
 	err := &APIError{Message: "something went wrong", Code: 400}
 	assert.Equal(t, "something went wrong", err.Error())
 }
+
+func TestAPIError_Class(t *testing.T) {
+	tests := map[int]ErrorClass{
+		0:   ErrorClassUnknown,
+		400: ErrorClassInvalid,
+		401: ErrorClassUnauthorized,
+		403: ErrorClassUnauthorized,
+		404: ErrorClassNotFound,
+		409: ErrorClassConflict,
+		422: ErrorClassInvalid,
+		429: ErrorClassRateLimited,
+		503: ErrorClassServer,
+	}
+	for code, class := range tests {
+		assert.Equal(t, class, (&APIError{Code: code}).Class(), code)
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	assert.Equal(t, ErrorClassNone, ClassifyError(nil))
+	assert.Equal(t, ErrorClassNotFound, ClassifyError(fmt.Errorf("get: %w", &APIError{Code: 404})))
+	assert.Equal(t, ErrorClassCanceled, ClassifyError(context.Canceled))
+	assert.Equal(t, ErrorClassUnavailable, ClassifyError(ErrCircuitOpen))
+	assert.Equal(t, ErrorClassUnavailable, ClassifyError(&net.OpError{Op: "dial", Err: errors.New("refused")}))
+	assert.Equal(t, ErrorClassUnknown, ClassifyError(errors.New("boom")))
+}
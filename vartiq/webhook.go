@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 )
 
 type WebhookService struct {
@@ -28,19 +29,37 @@ type WebhookAuth struct {
 	HMACSecret   string     `json:"hmacSecret,omitempty"`
 }
 
+// withoutSecrets returns a copy of the auth without the write-only
+// password, API key and HMAC secret
+func (a WebhookAuth) withoutSecrets() WebhookAuth {
+	a.Password, a.APIKey, a.HMACSecret = "", "", ""
+	return a
+}
+
 type Webhook struct {
-	ID            string       `json:"id"`
-	Name          string       `json:"name"`
-	URL           string       `json:"url"`
-	AppID         string       `json:"app"`
-	Secret        string       `json:"secret"`
-	CustomHeaders []Header     `json:"customHeaders"`
-	Headers       []Header     `json:"headers"`
-	Auth          *WebhookAuth `json:"auth,omitempty"`
-	CreatedAt     string       `json:"createdAt"`
-	UpdatedAt     string       `json:"updatedAt"`
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	URL           string            `json:"url"`
+	AppID         string            `json:"app"`
+	Secret        string            `json:"secret"`
+	CustomHeaders []Header          `json:"customHeaders"`
+	Headers       []Header          `json:"headers"`
+	Auth          *WebhookAuth      `json:"auth,omitempty"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+	EventTypes    []string          `json:"eventTypes,omitempty"`
+	RetryPolicy   *RetryPolicy      `json:"retryPolicy,omitempty"`
+	Status        WebhookStatus     `json:"status,omitempty"`
+	PausedUntil   Time              `json:"pausedUntil"`
+	CreatedAt     Time              `json:"createdAt"`
+	UpdatedAt     Time              `json:"updatedAt"`
 }
 
+// Created returns when the webhook was created
+func (w Webhook) Created() time.Time { return w.CreatedAt.Time }
+
+// Updated returns when the webhook was last updated
+func (w Webhook) Updated() time.Time { return w.UpdatedAt.Time }
+
 type Header struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
@@ -61,6 +80,13 @@ type CreateWebhookRequest struct {
 	// HMAC Auth
 	HMACHeader string `json:"hmacHeader,omitempty"`
 	HMACSecret string `json:"hmacSecret,omitempty"`
+	// Metadata labels the webhook, for example with its owning team
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// EventTypes subscribes the webhook to messages of these event types
+	// only. Empty subscribes it to every message sent to its app.
+	EventTypes []string `json:"eventTypes,omitempty"`
+	// RetryPolicy overrides how failed deliveries are retried
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
 }
 
 type WebhookResponse struct {
@@ -104,19 +130,28 @@ func (s *WebhookService) Create(ctx context.Context, req *CreateWebhookRequest)
 	if err := validateWebhookAuth(req); err != nil {
 		return nil, err
 	}
+	if err := validateRetryPolicy(req.RetryPolicy); err != nil {
+		return nil, err
+	}
 
 	// Convert the flattened request to the internal structure
 	requestBody := struct {
-		Name          string       `json:"name"`
-		URL           string       `json:"url"`
-		AppID         string       `json:"appId"`
-		CustomHeaders []Header     `json:"customHeaders,omitempty"`
-		Auth          *WebhookAuth `json:"auth,omitempty"`
+		Name          string            `json:"name"`
+		URL           string            `json:"url"`
+		AppID         string            `json:"appId"`
+		CustomHeaders []Header          `json:"customHeaders,omitempty"`
+		Auth          *WebhookAuth      `json:"auth,omitempty"`
+		Metadata      map[string]string `json:"metadata,omitempty"`
+		EventTypes    []string          `json:"eventTypes,omitempty"`
+		RetryPolicy   *RetryPolicy      `json:"retryPolicy,omitempty"`
 	}{
 		Name:          req.Name,
 		URL:           req.URL,
 		AppID:         req.AppID,
 		CustomHeaders: req.CustomHeaders,
+		Metadata:      req.Metadata,
+		EventTypes:    req.EventTypes,
+		RetryPolicy:   req.RetryPolicy,
 	}
 
 	if req.AuthMethod != "" {
@@ -132,8 +167,7 @@ func (s *WebhookService) Create(ctx context.Context, req *CreateWebhookRequest)
 	}
 
 	resp := &WebhookResponse{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+	_, err := s.client.request(ctx, "webhook.create").
 		SetBody(requestBody).
 		SetResult(resp).
 		Post("/webhooks")
@@ -143,23 +177,29 @@ func (s *WebhookService) Create(ctx context.Context, req *CreateWebhookRequest)
 	return resp, nil
 }
 
-func (s *WebhookService) GetAll(ctx context.Context, appID string) (*WebhookListResponse, error) {
+// GetAll lists the webhooks of an app, or those matching the label selector
+// given in opts
+func (s *WebhookService) GetAll(ctx context.Context, appID string, opts ...ListOption) (*WebhookListResponse, error) {
 	resp := &WebhookListResponse{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+	o, err := newListOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.client.request(ctx, "webhook.list").
 		SetQueryParam("appId", appID).
+		SetQueryParams(o.queryParams()).
 		SetResult(resp).
 		Get("/webhooks")
 	if err != nil {
 		return nil, err
 	}
+	resp.Data = filterLabels(resp.Data, o.selector, func(w Webhook) map[string]string { return w.Metadata })
 	return resp, nil
 }
 
 func (s *WebhookService) GetOne(ctx context.Context, webhookID string) (*WebhookResponse, error) {
 	resp := &WebhookResponse{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+	_, err := s.client.request(ctx, "webhook.get").
 		SetResult(resp).
 		Get("/webhooks/" + webhookID)
 	if err != nil {
@@ -170,8 +210,7 @@ func (s *WebhookService) GetOne(ctx context.Context, webhookID string) (*Webhook
 
 func (s *WebhookService) Update(ctx context.Context, webhookID string, req map[string]interface{}) (*WebhookResponse, error) {
 	resp := &WebhookResponse{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+	_, err := s.client.request(ctx, "webhook.update").
 		SetBody(req).
 		SetResult(resp).
 		Put("/webhooks/" + webhookID)
@@ -181,9 +220,81 @@ func (s *WebhookService) Update(ctx context.Context, webhookID string, req map[s
 	return resp, nil
 }
 
+// UpdateWebhookRequest is a typed webhook update. Empty fields are left
+// unchanged; a non-nil empty CustomHeaders, Metadata or EventTypes clears it
+// and RemoveAuth removes authentication. Clearing EventTypes subscribes the
+// webhook to every event, and RemoveRetryPolicy restores the default retries.
+type UpdateWebhookRequest struct {
+	Name          string
+	URL           string
+	CustomHeaders []Header
+	Auth          *WebhookAuth
+	RemoveAuth    bool
+	Metadata      map[string]string
+	EventTypes    []string
+	RetryPolicy   *RetryPolicy
+	// RemoveRetryPolicy restores the API's default retry policy
+	RemoveRetryPolicy bool
+}
+
+// body returns the fields to send to the API
+func (r *UpdateWebhookRequest) body() (map[string]interface{}, error) {
+	body := make(map[string]interface{})
+	if r.Name != "" {
+		body["name"] = r.Name
+	}
+	if r.URL != "" {
+		body["url"] = r.URL
+	}
+	if r.CustomHeaders != nil {
+		body["customHeaders"] = r.CustomHeaders
+	}
+	if r.Metadata != nil {
+		body["metadata"] = r.Metadata
+	}
+	if r.EventTypes != nil {
+		body["eventTypes"] = r.EventTypes
+	}
+	switch {
+	case r.RemoveRetryPolicy:
+		body["retryPolicy"] = nil
+	case r.RetryPolicy != nil:
+		if err := validateRetryPolicy(r.RetryPolicy); err != nil {
+			return nil, err
+		}
+		body["retryPolicy"] = r.RetryPolicy
+	}
+	switch {
+	case r.RemoveAuth:
+		body["auth"] = nil
+	case r.Auth != nil:
+		if err := validateWebhookAuth(&CreateWebhookRequest{
+			AuthMethod:   string(r.Auth.Method),
+			UserName:     r.Auth.UserName,
+			Password:     r.Auth.Password,
+			APIKey:       r.Auth.APIKey,
+			APIKeyHeader: r.Auth.APIKeyHeader,
+			HMACHeader:   r.Auth.HMACHeader,
+			HMACSecret:   r.Auth.HMACSecret,
+		}); err != nil {
+			return nil, err
+		}
+		body["auth"] = r.Auth
+	}
+	return body, nil
+}
+
+// UpdateFields updates a webhook with a typed request
+func (s *WebhookService) UpdateFields(ctx context.Context, webhookID string, req *UpdateWebhookRequest) (*WebhookResponse, error) {
+	body, err := req.body()
+	if err != nil {
+		return nil, err
+	}
+	return s.Update(ctx, webhookID, body)
+}
+
 func (s *WebhookService) Delete(ctx context.Context, webhookID string) error {
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+	_, err := s.client.request(ctx, "webhook.delete").
 		Delete("/webhooks/" + webhookID)
 	return err
 }
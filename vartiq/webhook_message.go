@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // Error represents an API error response
@@ -23,25 +24,33 @@ type WebhookMessage struct {
 	ID          string      `json:"id"`
 	AppID       string      `json:"app"`
 	Payload     interface{} `json:"payload"`
+	EventType   string      `json:"eventType,omitempty"`
 	Signature   string      `json:"signature"`
 	IsDelivered bool        `json:"isDelivered"`
-	CreatedAt   string      `json:"createdAt"`
-	UpdatedAt   string      `json:"updatedAt"`
+	CreatedAt   Time        `json:"createdAt"`
+	UpdatedAt   Time        `json:"updatedAt"`
 }
 
+// Created returns when the message was created
+func (w WebhookMessage) Created() time.Time { return w.CreatedAt.Time }
+
+// Updated returns when the message was last updated
+func (w WebhookMessage) Updated() time.Time { return w.UpdatedAt.Time }
+
 type webhookMessageResponse struct {
 	Data struct {
 		WebhookMessages []struct {
-			ID      string `json:"id"`
-			AppID   string `json:"app"`
-			Payload string `json:"payload"` // API returns payload as JSON string
-			Headers []struct {
+			ID        string `json:"id"`
+			AppID     string `json:"app"`
+			Payload   string `json:"payload"` // API returns payload as JSON string
+			EventType string `json:"eventType"`
+			Headers   []struct {
 				Key   string `json:"key"`
 				Value string `json:"value"`
 			} `json:"headers"`
-			IsDelivered bool   `json:"isDelivered"`
-			CreatedAt   string `json:"createdAt"`
-			UpdatedAt   string `json:"updatedAt"`
+			IsDelivered bool `json:"isDelivered"`
+			CreatedAt   Time `json:"createdAt"`
+			UpdatedAt   Time `json:"updatedAt"`
 		} `json:"webhookMessages"`
 	} `json:"data"`
 	Message string `json:"message"`
@@ -54,6 +63,30 @@ type WebhookMessageResponse struct {
 	Success bool           `json:"success"`
 }
 
+// MessageOption configures WebhookMessageService.CreateWithOptions
+type MessageOption func(*messageOptions)
+
+type messageOptions struct {
+	eventType string
+	headers   []Header
+}
+
+// WithEventType sets the event type of a message. Only webhooks subscribed to
+// the event type, or to every event, receive it.
+func WithEventType(eventType string) MessageOption {
+	return func(o *messageOptions) {
+		o.eventType = eventType
+	}
+}
+
+// WithMessageHeaders adds headers to the deliveries of a message, on top of
+// the custom headers of each webhook
+func WithMessageHeaders(headers ...Header) MessageOption {
+	return func(o *messageOptions) {
+		o.headers = append(o.headers, headers...)
+	}
+}
+
 // Create sends a message to a webhook. The payload can be any JSON-serializable value.
 // Example:
 //
@@ -61,23 +94,38 @@ type WebhookMessageResponse struct {
 //	    "hello": "world",
 //	})
 func (s *WebhookMessageService) Create(ctx context.Context, appID string, payload interface{}) (*WebhookMessageResponse, error) {
+	return s.CreateWithOptions(ctx, appID, payload)
+}
+
+// CreateWithOptions sends a message like Create, configured by opts.
+// Example:
+//
+//	message, err := client.WebhookMessage.CreateWithOptions(ctx, "APP_ID", order,
+//	    vartiq.WithEventType("order.created"))
+func (s *WebhookMessageService) CreateWithOptions(ctx context.Context, appID string, payload interface{}, opts ...MessageOption) (*WebhookMessageResponse, error) {
+	body := map[string]interface{}{
+		"appId":   appID,
+		"payload": payload,
+	}
+	var o messageOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.eventType != "" {
+		body["eventType"] = o.eventType
+	}
+	if len(o.headers) > 0 {
+		body["headers"] = o.headers
+	}
 	resp := &webhookMessageResponse{}
-	httpResp, err := s.client.resty.R().
-		SetContext(ctx).
-		SetBody(map[string]interface{}{
-			"appId":   appID,
-			"payload": payload,
-		}).
+	_, err := s.client.request(ctx, "webhook_message.create").
+		SetBody(body).
 		SetResult(resp).
 		Post("/webhook-messages")
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
 
-	// Debug logging
-	fmt.Printf("Response Status: %s\n", httpResp.Status())
-	fmt.Printf("Response Body: %s\n", string(httpResp.Body()))
-
 	if !resp.Success {
 		return nil, &Error{Message: resp.Message}
 	}
@@ -107,12 +155,17 @@ func (s *WebhookMessageService) Create(ctx context.Context, appID string, payloa
 		ID:          rawMessage.ID,
 		AppID:       appID, // Use the provided appID
 		Payload:     parsedPayload,
+		EventType:   rawMessage.EventType,
 		Signature:   signature,
 		IsDelivered: rawMessage.IsDelivered,
 		CreatedAt:   rawMessage.CreatedAt,
 		UpdatedAt:   rawMessage.UpdatedAt,
 	}
 
+	if s.client.metrics != nil {
+		s.client.metrics.ObserveMessageSent(appID)
+	}
+
 	return &WebhookMessageResponse{
 		Data:    message,
 		Message: resp.Message,
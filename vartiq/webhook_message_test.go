@@ -6,6 +6,8 @@ import (
 
 	"github.com/go-resty/resty/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/internal/apitest"
 )
 
 // Helper to create a WebhookMessageService with a mock client
@@ -27,3 +29,38 @@ func TestWebhookMessageService_Create(t *testing.T) {
 	assert.Error(t, err) // No server, should error
 	assert.Nil(t, message)
 }
+
+func TestWebhookMessageService_CreateWithEventType(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	ctx := context.Background()
+
+	webhook, err := client.Webhook.Create(ctx, &CreateWebhookRequest{Name: "w", URL: "https://a", AppID: "app-1", EventTypes: []string{"order.created"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"order.created"}, webhook.Data.EventTypes)
+
+	message, err := client.WebhookMessage.CreateWithOptions(ctx, "app-1", map[string]interface{}{"orderId": "42"}, WithEventType("order.created"))
+	require.NoError(t, err)
+	assert.Equal(t, "order.created", message.Data.EventType)
+
+	_, err = client.WebhookMessage.Create(ctx, "app-1", map[string]interface{}{})
+	require.NoError(t, err)
+	messages := api.Messages()
+	require.Len(t, messages, 2)
+	assert.Equal(t, "order.created", messages[0]["eventType"])
+	assert.NotContains(t, messages[1], "eventType")
+}
+
+func TestWebhookMessageService_CreateWithHeaders(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+
+	_, err := client.WebhookMessage.CreateWithOptions(context.Background(), "app-1", map[string]interface{}{},
+		WithMessageHeaders(Header{Key: "X-Request-Id", Value: "r-1"}))
+	require.NoError(t, err)
+	messages := api.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, []interface{}{map[string]interface{}{"key": "X-Request-Id", "value": "r-1"}}, messages[0]["headers"])
+}
@@ -0,0 +1,57 @@
+package vartiq
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RetryPolicy controls how the API retries failed deliveries to a webhook.
+// Zero fields use the API defaults. It configures the server side; see
+// RetryConfig for retrying the SDK's own requests.
+type RetryPolicy struct {
+	// MaxAttempts is how many times a message is sent before it is given
+	// up on, including the first attempt
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+	// Backoff is the delay before each retry. When there are more retries
+	// than delays, the last delay is used for the rest.
+	Backoff []Duration `json:"backoff,omitempty"`
+	// AttemptTimeout is how long each attempt may take before it counts as
+	// failed
+	AttemptTimeout Duration `json:"attemptTimeout,omitempty"`
+	// FailureStatusCodes are the response status codes that count as a
+	// failed attempt. Empty means any status outside 2xx.
+	FailureStatusCodes []int `json:"failureStatusCodes,omitempty"`
+}
+
+// Validate reports the first problem with the policy, such as a negative
+// attempt count or a status code outside 100-599. A nil policy is valid.
+func (p *RetryPolicy) Validate() error {
+	return validateRetryPolicy(p)
+}
+
+func validateRetryPolicy(p *RetryPolicy) error {
+	if p == nil {
+		return nil
+	}
+	if p.MaxAttempts < 0 {
+		return errors.New("retryPolicy.maxAttempts must not be negative")
+	}
+	if p.MaxAttempts > 0 && len(p.Backoff) > p.MaxAttempts-1 {
+		return fmt.Errorf("retryPolicy.backoff has %d delays but maxAttempts %d allows only %d retries",
+			len(p.Backoff), p.MaxAttempts, p.MaxAttempts-1)
+	}
+	for i, d := range p.Backoff {
+		if d <= 0 {
+			return fmt.Errorf("retryPolicy.backoff[%d] must be positive", i)
+		}
+	}
+	if p.AttemptTimeout < 0 {
+		return errors.New("retryPolicy.attemptTimeout must not be negative")
+	}
+	for _, code := range p.FailureStatusCodes {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("retryPolicy.failureStatusCodes: %d is not an HTTP status code", code)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package vartiq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/internal/apitest"
+)
+
+func TestValidateRetryPolicy(t *testing.T) {
+	valid := []*RetryPolicy{
+		nil,
+		{},
+		{MaxAttempts: 3, Backoff: []Duration{Duration(time.Second), Duration(time.Minute)}},
+		{Backoff: []Duration{Duration(time.Second)}, AttemptTimeout: Duration(10 * time.Second), FailureStatusCodes: []int{500, 503}},
+	}
+	for _, p := range valid {
+		assert.NoError(t, validateRetryPolicy(p), "%+v", p)
+	}
+
+	invalid := map[string]*RetryPolicy{
+		"maxAttempts must not be negative": {MaxAttempts: -1},
+		"allows only 1 retries":            {MaxAttempts: 2, Backoff: []Duration{Duration(time.Second), Duration(time.Second)}},
+		"backoff[1] must be positive":      {Backoff: []Duration{Duration(time.Second), 0}},
+		"attemptTimeout must not be":       {AttemptTimeout: -1},
+		"600 is not an HTTP status code":   {FailureStatusCodes: []int{500, 600}},
+	}
+	for message, p := range invalid {
+		assert.ErrorContains(t, p.Validate(), message)
+	}
+}
+
+func TestWebhookService_RetryPolicy(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	ctx := context.Background()
+
+	policy := &RetryPolicy{
+		MaxAttempts:        5,
+		Backoff:            []Duration{Duration(30 * time.Second), Duration(5 * time.Minute)},
+		AttemptTimeout:     Duration(10 * time.Second),
+		FailureStatusCodes: []int{429, 500, 502, 503},
+	}
+	created, err := client.Webhook.Create(ctx, &CreateWebhookRequest{Name: "w", URL: "https://a", AppID: "app-1", RetryPolicy: policy})
+	require.NoError(t, err)
+	assert.Equal(t, policy, created.Data.RetryPolicy)
+
+	_, err = client.Webhook.Create(ctx, &CreateWebhookRequest{Name: "w", URL: "https://a", AppID: "app-1", RetryPolicy: &RetryPolicy{MaxAttempts: -1}})
+	assert.Error(t, err)
+
+	updated, err := client.Webhook.UpdateFields(ctx, created.Data.ID, &UpdateWebhookRequest{RetryPolicy: &RetryPolicy{MaxAttempts: 2}})
+	require.NoError(t, err)
+	assert.Equal(t, &RetryPolicy{MaxAttempts: 2}, updated.Data.RetryPolicy)
+
+	_, err = client.Webhook.UpdateFields(ctx, created.Data.ID, &UpdateWebhookRequest{RetryPolicy: &RetryPolicy{FailureStatusCodes: []int{42}}})
+	assert.Error(t, err)
+
+	reset, err := client.Webhook.UpdateFields(ctx, created.Data.ID, &UpdateWebhookRequest{RemoveRetryPolicy: true})
+	require.NoError(t, err)
+	assert.Nil(t, reset.Data.RetryPolicy)
+	assert.Equal(t, 3, api.Count("PUT")+api.Count("POST"))
+}
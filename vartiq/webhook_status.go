@@ -0,0 +1,70 @@
+package vartiq
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// WebhookStatus is whether a webhook receives deliveries
+type WebhookStatus string
+
+const (
+	// WebhookStatusActive webhooks receive deliveries. Webhooks created
+	// before statuses existed report an empty status, which means active.
+	WebhookStatusActive WebhookStatus = "active"
+	// WebhookStatusPaused webhooks receive no deliveries until they are
+	// enabled or PausedUntil passes. Messages sent in the meantime are queued
+	// and delivered when the webhook resumes.
+	WebhookStatusPaused WebhookStatus = "paused"
+	// WebhookStatusDisabled webhooks receive no deliveries. Messages sent
+	// while a webhook is disabled are dropped.
+	WebhookStatusDisabled WebhookStatus = "disabled"
+)
+
+// StatusAt returns the status of the webhook at t. A paused webhook resumes
+// at PausedUntil, or stays paused until it is enabled if PausedUntil is zero.
+func (w Webhook) StatusAt(t time.Time) WebhookStatus {
+	switch {
+	case w.Status == "":
+		return WebhookStatusActive
+	case w.Status == WebhookStatusPaused && !w.PausedUntil.IsZero() && !t.Before(w.PausedUntil.Time):
+		return WebhookStatusActive
+	}
+	return w.Status
+}
+
+// Disable stops deliveries to a webhook without deleting it, so it keeps its
+// secret and configuration. Messages sent while it is disabled are dropped;
+// use Pause to keep them.
+func (s *WebhookService) Disable(ctx context.Context, webhookID string) (*WebhookResponse, error) {
+	return s.setStatus(ctx, "webhook.disable", webhookID, WebhookStatusDisabled, time.Time{})
+}
+
+// Enable resumes deliveries to a disabled or paused webhook. Messages queued
+// while it was paused are delivered.
+func (s *WebhookService) Enable(ctx context.Context, webhookID string) (*WebhookResponse, error) {
+	return s.setStatus(ctx, "webhook.enable", webhookID, WebhookStatusActive, time.Time{})
+}
+
+// Pause holds deliveries to a webhook until the given time, or until Enable is
+// called if until is zero. Messages sent while it is paused are queued and
+// delivered when it resumes.
+func (s *WebhookService) Pause(ctx context.Context, webhookID string, until time.Time) (*WebhookResponse, error) {
+	if !until.IsZero() && !until.After(time.Now()) {
+		return nil, errors.New("vartiq: pause must end in the future")
+	}
+	return s.setStatus(ctx, "webhook.pause", webhookID, WebhookStatusPaused, until)
+}
+
+func (s *WebhookService) setStatus(ctx context.Context, operation, webhookID string, status WebhookStatus, until time.Time) (*WebhookResponse, error) {
+	resp := &WebhookResponse{}
+	_, err := s.client.request(ctx, operation).
+		SetBody(map[string]interface{}{"status": status, "pausedUntil": Time{until.UTC()}}).
+		SetResult(resp).
+		Put("/webhooks/" + webhookID)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
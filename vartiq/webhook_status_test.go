@@ -0,0 +1,48 @@
+package vartiq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/internal/apitest"
+)
+
+func TestWebhookService_Status(t *testing.T) {
+	api := apitest.New()
+	defer api.Close()
+	client := New("key", api.URL)
+	ctx := context.Background()
+	id := api.Add(apitest.Webhooks, apitest.Object{"name": "w", "url": "https://a", "secret": "whsec"})
+
+	webhook, err := client.Webhook.GetOne(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, WebhookStatusActive, webhook.Data.StatusAt(time.Now()))
+
+	disabled, err := client.Webhook.Disable(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, WebhookStatusDisabled, disabled.Data.Status)
+	assert.Equal(t, "whsec", disabled.Data.Secret)
+
+	until := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	paused, err := client.Webhook.Pause(ctx, id, until)
+	require.NoError(t, err)
+	assert.Equal(t, WebhookStatusPaused, paused.Data.Status)
+	assert.True(t, until.Equal(paused.Data.PausedUntil.Time))
+	assert.Equal(t, WebhookStatusPaused, paused.Data.StatusAt(time.Now()))
+	assert.Equal(t, WebhookStatusActive, paused.Data.StatusAt(until))
+
+	enabled, err := client.Webhook.Enable(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, WebhookStatusActive, enabled.Data.Status)
+	assert.True(t, enabled.Data.PausedUntil.IsZero())
+
+	indefinite, err := client.Webhook.Pause(ctx, id, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, WebhookStatusPaused, indefinite.Data.StatusAt(time.Now().Add(24*time.Hour)))
+
+	_, err = client.Webhook.Pause(ctx, id, time.Now().Add(-time.Minute))
+	assert.Error(t, err)
+}